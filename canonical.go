@@ -0,0 +1,221 @@
+package refyne
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+)
+
+// canonicalKeepFields lists the types whose fields are not all written from the object struct,
+// so none of their fields can be left out.
+var canonicalKeepFields = map[string]bool{
+	"*fyne.Container":          true,
+	"*widget.Accordion":        true,
+	"*widget.Form":             true,
+	"*widget.Toolbar":          true,
+	"*container.AppTabs":       true,
+	"*container.Clip":          true,
+	"*container.Navigation":    true,
+	"*container.Scroll":        true,
+	"*container.Split":         true,
+	"*container.ThemeOverride": true,
+}
+
+// canonicalEncoder rewrites an encoded tree into its canonical form.
+// The zero field values of each type are cached as they are found.
+type canonicalEncoder struct {
+	ctx      Context
+	defaults map[string]map[string]interface{}
+}
+
+// canonicalTree returns the canonical form of an encoded tree.
+// All objects become maps so that every key is sorted, fields that hold the zero value of their type
+// are removed along with empty properties, and numbers are written in their shortest form.
+// Zero values do not depend on how a type creates new objects, so the removed fields can always be restored.
+func canonicalTree(tree interface{}, d Context) (interface{}, error) {
+	enc := &canonicalEncoder{ctx: d, defaults: make(map[string]map[string]interface{})}
+	return enc.canonical(tree)
+}
+
+func (c *canonicalEncoder) canonical(tree interface{}) (interface{}, error) {
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var generic interface{}
+	if err = d.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	return c.value(generic), nil
+}
+
+func (c *canonicalEncoder) value(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		return canonicalNumber(t)
+	case []interface{}:
+		for i, item := range t {
+			t[i] = c.value(item)
+		}
+		return t
+	case map[string]interface{}:
+		for k, item := range t {
+			t[k] = c.value(item)
+		}
+		c.object(t)
+		return t
+	}
+
+	return v
+}
+
+// object removes the parts of a node in the tree that will be restored when it is decoded.
+func (c *canonicalEncoder) object(m map[string]interface{}) {
+	class, ok := m["Type"].(string)
+	if !ok {
+		return
+	}
+	for _, key := range []string{"Name", "Actions", "Properties"} {
+		if val, ok := m[key]; ok && isEmptyJSON(val) {
+			delete(m, key)
+		}
+	}
+
+	fields, ok := m["Struct"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	defaults := c.zeroFields(class)
+	for k, val := range fields {
+		def, ok := defaults[k]
+		if !ok {
+			continue
+		}
+		if reflect.DeepEqual(def, val) {
+			delete(fields, k)
+			continue
+		}
+		removeDefaultMembers(val, def)
+	}
+}
+
+// removeDefaultMembers removes the members of a nested struct, such as a TextStyle, that hold the zero value,
+// as the decoder builds nested structs from their zero value.
+// Objects in the tree are left alone, as they are compared with the zero values for their own type.
+func removeDefaultMembers(val, def interface{}) {
+	m, ok := val.(map[string]interface{})
+	defaults, isMap := def.(map[string]interface{})
+	if !ok || !isMap {
+		return
+	}
+	if _, isObject := m["Type"]; isObject {
+		return
+	}
+
+	for k, v := range m {
+		d, ok := defaults[k]
+		if !ok {
+			continue
+		}
+		if isZeroJSON(v) && reflect.DeepEqual(d, v) {
+			delete(m, k)
+			continue
+		}
+		removeDefaultMembers(v, d)
+	}
+}
+
+// zeroFields returns the encoded fields of a zero value of the given type.
+// The decoder sets any field missing from a canonical document to its zero value.
+func (c *canonicalEncoder) zeroFields(class string) map[string]interface{} {
+	if def, ok := c.defaults[class]; ok {
+		return def
+	}
+	c.defaults[class] = nil
+
+	reg := guidefs.RegistryOf(c.ctx)
+	info := reg.Lookup(class)
+	if canonicalKeepFields[class] || info == nil || info.Create == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(info.Create(ContextWithRegistry(reg)))
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	data, err := json.Marshal(encodeStruct(reflect.New(t.Elem()).Interface(), c.ctx))
+	if err != nil {
+		return nil
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var fields map[string]interface{}
+	if err = d.Decode(&fields); err != nil {
+		return nil
+	}
+
+	for k, item := range fields {
+		fields[k] = c.value(item)
+	}
+	c.defaults[class] = fields
+	return fields
+}
+
+// canonicalNumber writes a number in its shortest form, removing the noise of float32 values
+// that were widened to float64, such as 0.10000000149011612.
+func canonicalNumber(n json.Number) json.Number {
+	if _, err := n.Int64(); err == nil {
+		return n
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return n
+	}
+
+	if f == 0 {
+		return "0"
+	}
+	bits := 64
+	if float64(float32(f)) == f {
+		bits = 32
+	}
+	return json.Number(strconv.FormatFloat(f, 'f', -1, bits))
+}
+
+// isZeroJSON returns true if a value is the JSON form of a zero value: false, 0, an empty string or null.
+func isZeroJSON(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !t
+	case string:
+		return t == ""
+	case json.Number:
+		f, err := t.Float64()
+		return err == nil && f == 0
+	}
+	return false
+}
+
+func isEmptyJSON(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case map[string]interface{}:
+		return len(t) == 0
+	case []interface{}:
+		return len(t) == 0
+	}
+	return false
+}
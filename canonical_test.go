@@ -0,0 +1,123 @@
+package refyne
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"image/color"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeObjectCanonical(t *testing.T) {
+	l := widget.NewLabelWithStyle("Hi", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	meta := map[fyne.CanvasObject]map[string]string{l: {"name": "myLabel"}}
+
+	var buf bytes.Buffer
+	err := EncodeObjectWithOptions(l, &context{meta: meta}, &buf, EncodeOptions{Canonical: true})
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "Canonical": true,
+  "Object": {
+    "Name": "myLabel",
+    "Struct": {
+      "Alignment": 1,
+      "Text": "Hi",
+      "TextStyle": {
+        "Bold": true
+      }
+    },
+    "Type": "*widget.Label"
+  },
+  "Version": 1
+}
+`, buf.String())
+
+	obj, err := DecodeObject(&buf, DefaultContext())
+	require.NoError(t, err)
+	assert.Equal(t, fyne.TextStyle{Bold: true}, obj.(*widget.Label).TextStyle)
+	assert.Equal(t, fyne.TextAlignCenter, obj.(*widget.Label).Alignment)
+}
+
+func TestEncodeObjectCanonicalStable(t *testing.T) {
+	r := canvas.NewRectangle(color.White)
+	r.StrokeColor = color.Black
+	r.StrokeWidth = 0.1
+	s := container.NewHSplit(widget.NewLabel("Label"), r)
+	s.Offset = 0.3
+	meta := map[fyne.CanvasObject]map[string]string{s: {"name": "split", "a": "1", "b": "2", "c": "3"}}
+
+	encode := func(obj fyne.CanvasObject, ctx Context) string {
+		var buf bytes.Buffer
+		require.NoError(t, EncodeObjectWithOptions(obj, ctx, &buf, EncodeOptions{Canonical: true}))
+		return buf.String()
+	}
+	first := encode(s, &context{meta: meta})
+	assert.Equal(t, first, encode(s, &context{meta: meta}))
+	assert.Contains(t, first, `"StrokeWidth": 0.1`)
+	assert.NotContains(t, first, "0.10000000149011612")
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(first), &doc))
+	leading := doc["Object"].(map[string]interface{})["Struct"].(map[string]interface{})["Leading"]
+	assert.Equal(t, map[string]interface{}{"Type": "*widget.Label", "Struct": map[string]interface{}{"Text": "Label"}}, leading)
+
+	ctx := DefaultContext()
+	obj, err := DecodeObject(bytes.NewReader([]byte(first)), ctx)
+	require.NoError(t, err)
+	split := obj.(*container.Split)
+	assert.Equal(t, "Label", split.Leading.(*widget.Label).Text)
+	assert.Equal(t, float32(0.1), split.Trailing.(*canvas.Rectangle).StrokeWidth)
+	assert.Equal(t, first, encode(obj, ctx))
+}
+
+func TestEncodeObjectCanonicalReplacedCreate(t *testing.T) {
+	l := widget.NewLabel("Hi")
+	var buf bytes.Buffer
+	require.NoError(t, EncodeObjectWithOptions(l, DefaultContext(), &buf, EncodeOptions{Canonical: true}))
+	assert.NotContains(t, buf.String(), "Alignment")
+	assert.NotContains(t, buf.String(), "Importance")
+
+	reg := NewRegistry()
+	info, _ := reg.Widget("*widget.Label")
+	replaced := info
+	replaced.Create = func(d Context) fyne.CanvasObject {
+		l := info.Create(d).(*widget.Label)
+		l.Alignment = fyne.TextAlignCenter
+		l.Importance = widget.HighImportance
+		return l
+	}
+	_, ok := reg.Replace("*widget.Label", replaced)
+	require.True(t, ok)
+
+	ctx := ContextWithRegistry(reg)
+	obj, err := DecodeObject(bytes.NewReader(buf.Bytes()), ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi", obj.(*widget.Label).Text)
+	assert.Equal(t, fyne.TextAlignLeading, obj.(*widget.Label).Alignment)
+	assert.Equal(t, widget.MediumImportance, obj.(*widget.Label).Importance)
+
+	var again bytes.Buffer
+	require.NoError(t, EncodeObjectWithOptions(obj, ctx, &again, EncodeOptions{Canonical: true}))
+	assert.Equal(t, buf.String(), again.String())
+
+	streamed, err := DecodeObjectStream(gocontext.Background(), bytes.NewReader(buf.Bytes()), ContextWithRegistry(reg), DecodeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, fyne.TextAlignLeading, streamed.(*widget.Label).Alignment)
+	assert.Equal(t, widget.MediumImportance, streamed.(*widget.Label).Importance)
+
+	created := replaced.Create(ctx)
+	buf.Reset()
+	require.NoError(t, EncodeObjectWithOptions(created, ctx, &buf, EncodeOptions{Canonical: true}))
+	obj, err = DecodeObject(&buf, DefaultContext())
+	require.NoError(t, err)
+	assert.Equal(t, fyne.TextAlignCenter, obj.(*widget.Label).Alignment)
+	assert.Equal(t, widget.HighImportance, obj.(*widget.Label).Importance)
+}
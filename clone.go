@@ -0,0 +1,218 @@
+package refyne
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+// Clone returns a deep copy of an object and the objects inside it, with their metadata and attributes.
+// Objects that have a name are given a new, unique, name so that the copy can be added to the same GUI.
+func Clone(obj fyne.CanvasObject, d Context) (fyne.CanvasObject, error) {
+	tree, err := EncodeMap(obj, d)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	docs, err := componentDocuments(obj, d)
+	if err != nil {
+		return nil, err
+	}
+	opts := DecodeOptions{LoadComponent: componentLoader(docs)}
+	clone, err := DecodeMapWithOptions(m, d, opts)
+	if clone == nil {
+		return nil, err
+	}
+	copyAttrs(obj, clone, d)
+	renameObjects(clone, d)
+	return clone, err
+}
+
+// clipboardDocument is a document with the content of any components that it uses,
+// so that it can be pasted where the component files cannot be found.
+type clipboardDocument struct {
+	Version    int
+	Object     interface{}
+	Components map[string]json.RawMessage `json:",omitempty"`
+}
+
+// CopyToClipboardFormat returns a text representation of an object and the objects inside it,
+// which can be placed on the clipboard and later passed to `Paste`, in this or another GUI.
+// The objects of any components are included so that they do not need to be loaded again.
+func CopyToClipboardFormat(obj fyne.CanvasObject, d Context) (string, error) {
+	tree, err := EncodeMap(obj, d)
+	if err != nil {
+		return "", err
+	}
+
+	docs, err := componentDocuments(obj, d)
+	if err != nil {
+		return "", err
+	}
+
+	doc := &clipboardDocument{Version: FormatVersion, Object: tree}
+	if len(docs) > 0 {
+		doc.Components = docs
+	}
+	data, err := json.Marshal(doc)
+	return string(data), err
+}
+
+// Paste creates the objects from text returned by `CopyToClipboardFormat` and inserts them into the
+// parent container at the given index. Objects that have a name which is already in use are renamed.
+// The new object is returned, or an error if the text could not be decoded or the parent cannot hold it.
+func Paste(text string, parent fyne.CanvasObject, index int, d Context) (fyne.CanvasObject, error) {
+	_, children, err := treeInfo(parent, d)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index > len(children) {
+		return nil, fmt.Errorf("index %d out of range for %d children", index, len(children))
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		return nil, err
+	}
+
+	docs := make(map[string]json.RawMessage)
+	if m, ok := data.(map[string]interface{}); ok {
+		components, _ := m["Components"].(map[string]interface{})
+		for ref, doc := range components {
+			docs[ref], _ = json.Marshal(doc)
+		}
+	}
+
+	forget := forgetNewObjects(d)
+	obj, err := decodeDocument(data, d, DecodeOptions{LoadComponent: componentLoader(docs)})
+	if obj == nil {
+		forget()
+		if err == nil {
+			err = errors.New("no object found to paste")
+		}
+		return nil, err
+	}
+	renameObjects(obj, d)
+
+	if insertErr := InsertChild(parent, index, obj, d); insertErr != nil {
+		forget()
+		return nil, insertErr
+	}
+	return obj, err
+}
+
+// forgetNewObjects returns a function that removes the metadata and attributes of every object
+// added to the context after it was called, so that a decoded tree which is not used leaves nothing behind.
+func forgetNewObjects(d Context) func() {
+	known := make(map[fyne.CanvasObject]bool, len(d.Metadata())+len(d.Attrs()))
+	for o := range d.Metadata() {
+		known[o] = true
+	}
+	for o := range d.Attrs() {
+		known[o] = true
+	}
+
+	return func() {
+		for o := range d.Metadata() {
+			if !known[o] {
+				delete(d.Metadata(), o)
+			}
+		}
+		for o := range d.Attrs() {
+			if !known[o] {
+				delete(d.Attrs(), o)
+			}
+		}
+	}
+}
+
+// copyAttrs copies the attributes of the objects in a tree to the objects in the same place of a copied tree.
+func copyAttrs(from, to fyne.CanvasObject, d Context) {
+	if from == nil || to == nil {
+		return
+	}
+
+	if attrs := d.Attrs()[from]; len(attrs) > 0 {
+		d.Attrs()[to] = append([]string(nil), attrs...)
+	}
+
+	_, fromChildren, _ := treeInfo(from, d)
+	_, toChildren, _ := treeInfo(to, d)
+	for i := 0; i < len(fromChildren) && i < len(toChildren); i++ {
+		copyAttrs(fromChildren[i], toChildren[i], d)
+	}
+}
+
+// renameObjects gives a unique name to each named object in a new tree, where the name is used elsewhere.
+// Names that were generated for code export are removed, so that new ones are generated.
+func renameObjects(root fyne.CanvasObject, d Context) {
+	tree := map[fyne.CanvasObject]bool{}
+	var objs []fyne.CanvasObject
+	var walk func(fyne.CanvasObject)
+	walk = func(o fyne.CanvasObject) {
+		if o == nil || tree[o] {
+			return
+		}
+		tree[o] = true
+		objs = append(objs, o)
+
+		_, children, _ := treeInfo(o, d)
+		for _, child := range children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	used := map[string]bool{}
+	for o, props := range d.Metadata() {
+		if !tree[o] && props["name"] != "" {
+			used[props["name"]] = true
+		}
+	}
+
+	for _, o := range objs {
+		props := d.Metadata()[o]
+		name := props["name"]
+		if name == "" {
+			continue
+		}
+		if props["name-is-generated"] == "1" {
+			delete(props, "name")
+			delete(props, "name-is-generated")
+			continue
+		}
+
+		if used[name] {
+			name = uniqueName(name, used)
+			props["name"] = name
+		}
+		used[name] = true
+	}
+}
+
+// uniqueName returns a name that is not used, by counting up from the name without any number at the end.
+func uniqueName(name string, used map[string]bool) string {
+	base := strings.TrimRight(name, "0123456789")
+	if base == "" {
+		base = name
+	}
+
+	for i := 1; ; i++ {
+		next := base + strconv.Itoa(i)
+		if !used[next] {
+			return next
+		}
+	}
+}
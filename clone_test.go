@@ -0,0 +1,105 @@
+package refyne
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	d := DefaultContext()
+	save := widget.NewButton("Save", func() {})
+	d.Metadata()[save] = map[string]string{"name": "saveButton"}
+	d.Attrs()[save] = []string{"Disable()"}
+	label := widget.NewLabel("Status")
+	d.Metadata()[label] = map[string]string{"name": "label2"}
+	card := widget.NewCard("Title", "", container.NewVBox(label, save))
+	d.Metadata()[card] = map[string]string{}
+
+	obj, err := Clone(card, d)
+	require.NoError(t, err)
+	clone := obj.(*widget.Card)
+	assert.NotSame(t, card, clone)
+	assert.Equal(t, "Title", clone.Title)
+
+	objs := clone.Content.(*fyne.Container).Objects
+	require.Len(t, objs, 2)
+	assert.NotSame(t, save, objs[1])
+	assert.Equal(t, "label1", d.Metadata()[objs[0]]["name"])
+	assert.Equal(t, "saveButton1", d.Metadata()[objs[1]]["name"])
+	assert.Equal(t, []string{"Disable()"}, d.Attrs()[objs[1]])
+	assert.Equal(t, "saveButton", d.Metadata()[save]["name"])
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ExportGo(container.NewVBox(card, clone), d, "cloned", buf))
+	assert.Equal(t, 1, strings.Count(buf.String(), "saveButton1 *widget.Button"))
+	assert.Equal(t, 1, strings.Count(buf.String(), "saveButton  *widget.Button"))
+}
+
+func TestCopyPaste(t *testing.T) {
+	d := DefaultContext()
+	entry := widget.NewEntry()
+	d.Metadata()[entry] = map[string]string{"name": "email"}
+	text, err := CopyToClipboardFormat(entry, d)
+	require.NoError(t, err)
+
+	top, bottom := widget.NewLabel("top"), widget.NewLabel("bottom")
+	border := newTestBorder(d, top, entry, bottom)
+	pasted, err := Paste(text, border, 0, d)
+	require.NoError(t, err)
+	assert.IsType(t, &widget.Entry{}, pasted)
+	assert.Equal(t, pasted, border.Objects[0])
+	assert.Equal(t, "email1", d.Metadata()[pasted]["name"])
+	assert.Equal(t, "1", d.Metadata()[border]["top"])
+	assert.Equal(t, "3", d.Metadata()[border]["bottom"])
+
+	other := DefaultContext()
+	list := container.NewVBox()
+	other.Metadata()[list] = map[string]string{"layout": "VBox"}
+	pasted, err = Paste(text, list, 0, other)
+	require.NoError(t, err)
+	assert.Equal(t, "email", other.Metadata()[pasted]["name"])
+
+	_, err = Paste("not json", list, 0, other)
+	assert.Error(t, err)
+	_, err = Paste(text, list, 5, other)
+	assert.Error(t, err)
+	assert.Len(t, list.Objects, 1)
+}
+
+func TestPasteFailureForgetsObjects(t *testing.T) {
+	d := DefaultContext()
+	name, email := widget.NewEntry(), widget.NewEntry()
+	form := container.NewVBox(name, container.NewHBox(email))
+	d.Metadata()[name] = map[string]string{"name": "name"}
+	d.Metadata()[email] = map[string]string{"name": "email"}
+	d.Attrs()[email] = []string{"required"}
+	text, err := CopyToClipboardFormat(form, d)
+	require.NoError(t, err)
+
+	other := DefaultContext()
+	scroll := container.NewVScroll(widget.NewLabel("content"))
+	other.Metadata()[scroll] = map[string]string{"name": "scroll"}
+	_, err = Paste(text, scroll, 0, other)
+	assert.Error(t, err)
+	assert.Len(t, other.Metadata(), 1)
+	assert.Empty(t, other.Attrs())
+
+	list := container.NewVBox()
+	_, err = Paste(text, list, 1, other)
+	assert.ErrorContains(t, err, "out of range")
+	assert.Len(t, other.Metadata(), 1)
+	_, err = Paste("not json", list, 1, other)
+	assert.ErrorContains(t, err, "out of range")
+
+	pasted, err := Paste(text, list, 0, other)
+	require.NoError(t, err)
+	assert.Equal(t, "name", other.Metadata()[pasted.(*fyne.Container).Objects[0]]["name"])
+}
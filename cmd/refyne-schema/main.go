@@ -0,0 +1,33 @@
+// Command refyne-schema writes the JSON Schema for GUI documents to standard output or a file.
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/fyne-io/refyne"
+)
+
+func main() {
+	out := flag.String("o", "", "the file to write the schema to, defaults to standard output")
+	flag.Parse()
+	test.NewApp() // widgets are created to inspect their fields, this needs an app but no display
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalln("Failed to create schema file:", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := refyne.WriteJSONSchema(w); err != nil {
+		log.Fatalln("Failed to write schema:", err)
+	}
+}
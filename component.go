@@ -0,0 +1,225 @@
+package refyne
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+)
+
+// componentType is the node type of a reference to another GUI document.
+const componentType = "Component"
+
+// componentObj is the JSON of a component reference, which is written in place of the objects it loaded.
+type componentObj struct {
+	Type      string
+	Component string
+	Overrides json.RawMessage `json:",omitempty"`
+}
+
+// encodeComponent returns the reference to a component that was loaded from another GUI document.
+func encodeComponent(props map[string]string) interface{} {
+	node := &componentObj{Type: componentType, Component: props[guidefs.ComponentKey]}
+	if overrides := props[guidefs.ComponentOverridesKey]; overrides != "" {
+		node.Overrides = json.RawMessage(overrides)
+	}
+	return node
+}
+
+// loadComponentFile opens the GUI document for a component, the ".gui.json" extension may be left out.
+func loadComponentFile(ref string) (io.ReadCloser, error) {
+	f, err := os.Open(ref)
+	if err == nil || !os.IsNotExist(err) || strings.HasSuffix(ref, ".json") {
+		return f, err
+	}
+
+	return os.Open(ref + ".gui.json")
+}
+
+// decodeComponent loads the GUI document that a component refers to and decodes its objects.
+// Overrides are applied to the named objects inside it and the reference is stored in the metadata of the root.
+func (dec *decoder) decodeComponent(m map[string]interface{}, path string) fyne.CanvasObject {
+	ref, ok := m["Component"].(string)
+	if !ok || ref == "" {
+		return dec.placeholder(m, joinPath(path, "Component"), componentType, "Component should be a path or name")
+	}
+	file := ref
+	if dec.opts.LoadComponent == nil && dec.dir != "" && !filepath.IsAbs(ref) {
+		file = filepath.Join(dec.dir, ref)
+	}
+	for _, loading := range dec.loading {
+		if loading == file {
+			return dec.placeholder(m, path, componentType, "component "+ref+" includes itself")
+		}
+	}
+
+	root, err := dec.loadComponent(file)
+	if err != nil {
+		return dec.placeholder(m, path, componentType, fmt.Sprintf("failed to load component %s: %v", ref, err))
+	}
+
+	sub := &decoder{ctx: dec.ctx, opts: dec.opts, loading: append(append([]string(nil), dec.loading...), file)}
+	if dec.opts.LoadComponent == nil {
+		sub.dir = filepath.Dir(file)
+	}
+	var obj fyne.CanvasObject
+	if root = sub.upgradeDocument(root); root != nil {
+		obj = sub.decodeMap(root, "")
+	}
+	prefix := joinPath(path, "Component("+ref+")")
+	for _, issue := range sub.issues {
+		if issue.Path == "" {
+			issue.Path = prefix
+		} else {
+			issue.Path = prefix + "." + issue.Path
+		}
+		dec.issues = append(dec.issues, issue)
+	}
+	if obj == nil {
+		return nil
+	}
+
+	props := dec.ctx.Metadata()[obj]
+	if props == nil {
+		props = make(map[string]string)
+		dec.ctx.Metadata()[obj] = props
+	}
+	props[guidefs.ComponentKey] = ref
+	if overrides, ok := m["Overrides"]; ok && overrides != nil {
+		if dec.decodeOverrides(obj, overrides, joinPath(path, "Overrides")) {
+			data, _ := json.Marshal(overrides)
+			props[guidefs.ComponentOverridesKey] = string(data)
+		}
+	}
+	return obj
+}
+
+// loadComponent reads the document of a component using the configured loader.
+func (dec *decoder) loadComponent(ref string) (map[string]interface{}, error) {
+	load := dec.opts.LoadComponent
+	if load == nil {
+		load = loadComponentFile
+	}
+	r, err := load(ref)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var data interface{}
+	if err = json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, err
+	}
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("document root is not an object")
+	}
+	return m, nil
+}
+
+// decodeOverrides sets the fields of named objects inside a component, returning false if the data was invalid.
+func (dec *decoder) decodeOverrides(root fyne.CanvasObject, data interface{}, path string) bool {
+	overrides, ok := data.(map[string]interface{})
+	if !ok {
+		dec.report(path, componentType, SeverityWarning, "Overrides should be an object")
+		return false
+	}
+
+	named := map[string]fyne.CanvasObject{}
+	var walk func(fyne.CanvasObject)
+	walk = func(o fyne.CanvasObject) {
+		if o == nil {
+			return
+		}
+		if name := dec.ctx.Metadata()[o]["name"]; name != "" {
+			named[name] = o
+		}
+		_, children, _ := treeInfo(o, dec.ctx)
+		for _, child := range children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names) // report issues in a stable order
+
+	for _, name := range names {
+		objPath := joinPath(path, name)
+		obj, ok := named[name]
+		if !ok {
+			dec.report(objPath, componentType, SeverityWarning, "component has no object named "+name)
+			continue
+		}
+		fields, ok := overrides[name].(map[string]interface{})
+		if !ok {
+			dec.report(objPath, componentType, SeverityWarning, "overrides of "+name+" should be an object")
+			continue
+		}
+
+		e := reflect.ValueOf(obj)
+		if e.Kind() != reflect.Ptr || e.Elem().Kind() != reflect.Struct {
+			dec.report(objPath, componentType, SeverityWarning, "fields of "+name+" cannot be set")
+			continue
+		}
+		dec.decodeFields(e.Elem(), fields, objPath, guidefs.RegistryOf(dec.ctx).TypeName(obj))
+		obj.Refresh()
+	}
+	return true
+}
+
+// componentDocuments returns the documents of the components used in a tree, keyed by reference,
+// so that the objects can be decoded again without loading the components.
+func componentDocuments(obj fyne.CanvasObject, d Context) (map[string]json.RawMessage, error) {
+	docs := make(map[string]json.RawMessage)
+	var walk func(fyne.CanvasObject) error
+	walk = func(o fyne.CanvasObject) error {
+		if o == nil {
+			return nil
+		}
+
+		if ref := d.Metadata()[o][guidefs.ComponentKey]; ref != "" {
+			if _, ok := docs[ref]; !ok {
+				doc, err := encodeDocument(o, d, EncodeOptions{expandComponent: true})
+				if err != nil {
+					return err
+				}
+				if docs[ref], err = json.Marshal(doc); err != nil {
+					return err
+				}
+			}
+		}
+
+		_, children, _ := treeInfo(o, d)
+		for _, child := range children {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return docs, walk(obj)
+}
+
+// componentLoader returns a function for `DecodeOptions.LoadComponent` that reads the documents provided,
+// falling back to loading from a file for other references.
+func componentLoader(docs map[string]json.RawMessage) func(string) (io.ReadCloser, error) {
+	return func(ref string) (io.ReadCloser, error) {
+		if doc, ok := docs[ref]; ok {
+			return io.NopCloser(bytes.NewReader(doc)), nil
+		}
+		return loadComponentFile(ref)
+	}
+}
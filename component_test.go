@@ -0,0 +1,152 @@
+package refyne
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const headerComponentJSON = `{"Version": 1, "Object": {"Type": "*fyne.Container", "Layout": "VBox", "Objects": [
+	{"Type": "*widget.Label", "Name": "title", "Struct": {"Text": "Header"}}]}}`
+
+func loadTestComponent(ref string) (io.ReadCloser, error) {
+	switch ref {
+	case "screens/header.gui.json":
+		return io.NopCloser(strings.NewReader(headerComponentJSON)), nil
+	case "loop":
+		return io.NopCloser(strings.NewReader(`{"Type": "Component", "Component": "loop"}`)), nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestDecodeComponent(t *testing.T) {
+	d := DefaultContext()
+	in := `{"Type": "*fyne.Container", "Layout": "VBox", "Objects": [
+		{"Type": "Component", "Component": "screens/header.gui.json", "Overrides": {"title": {"Text": "Settings"}}},
+		{"Type": "*widget.Button", "Struct": {"Text": "Save"}}]}`
+
+	obj, err := DecodeObjectWithOptions(strings.NewReader(in), d, DecodeOptions{LoadComponent: loadTestComponent})
+	require.NoError(t, err)
+	objs := obj.(*fyne.Container).Objects
+	require.Len(t, objs, 2)
+	header := objs[0].(*fyne.Container)
+	require.Len(t, header.Objects, 1)
+	assert.Equal(t, "Settings", header.Objects[0].(*widget.Label).Text)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, EncodeObject(obj, d, buf))
+	assert.Contains(t, buf.String(), `"Component": "screens/header.gui.json"`)
+	assert.Contains(t, buf.String(), `"Text": "Settings"`)
+	assert.NotContains(t, buf.String(), "Header")
+
+	buf.Reset()
+	require.NoError(t, ExportGo(obj, d, "main", buf))
+	assert.Contains(t, buf.String(), "comp := newHeaderGUI()")
+	assert.Contains(t, buf.String(), `comp.title.Text = "Settings"`)
+	assert.NotContains(t, buf.String(), "title *widget.Label")
+}
+
+func TestDecodeComponentErrors(t *testing.T) {
+	d := DefaultContext()
+	opts := DecodeOptions{LoadComponent: loadTestComponent}
+
+	_, err := DecodeObjectWithOptions(strings.NewReader(`{"Type": "Component", "Component": "missing"}`), d, opts)
+	var decodeErr *DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	assert.Contains(t, decodeErr.Issues[0].Message, "failed to load component missing")
+
+	_, err = DecodeObjectWithOptions(strings.NewReader(`{"Type": "Component", "Component": "loop"}`), d, opts)
+	require.True(t, errors.As(err, &decodeErr))
+	require.Len(t, decodeErr.Issues, 1)
+	assert.Equal(t, "Component(loop)", decodeErr.Issues[0].Path)
+	assert.Contains(t, decodeErr.Issues[0].Message, "includes itself")
+}
+
+func TestExportGoComponentRoot(t *testing.T) {
+	d := DefaultContext()
+	obj, err := DecodeObjectWithOptions(strings.NewReader(`{"Type": "Component", "Component": "screens/header.gui.json"}`),
+		d, DecodeOptions{LoadComponent: loadTestComponent})
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ExportGo(obj, d, "main", buf))
+	assert.Contains(t, buf.String(), "newHeaderGUI().makeUI()")
+}
+
+func TestCloneComponent(t *testing.T) {
+	d := DefaultContext()
+	in := `{"Type": "*fyne.Container", "Layout": "VBox", "Objects": [
+		{"Type": "Component", "Component": "screens/header.gui.json", "Overrides": {"title": {"Text": "Settings"}}}]}`
+	obj, err := DecodeObjectWithOptions(strings.NewReader(in), d, DecodeOptions{LoadComponent: loadTestComponent})
+	require.NoError(t, err)
+	root := obj.(*fyne.Container)
+	header := root.Objects[0]
+
+	clone, err := Clone(header, d)
+	require.NoError(t, err)
+	require.NotNil(t, clone)
+	assert.NotSame(t, header, clone)
+	assert.Equal(t, "Settings", clone.(*fyne.Container).Objects[0].(*widget.Label).Text)
+	assert.Equal(t, "screens/header.gui.json", d.Metadata()[clone][guidefs.ComponentKey])
+
+	text, err := CopyToClipboardFormat(root, d)
+	require.NoError(t, err)
+	other := DefaultContext()
+	parent := container.NewVBox()
+	other.Metadata()[parent] = map[string]string{"layout": "VBox"}
+	pasted, err := Paste(text, parent, 0, other)
+	require.NoError(t, err)
+	inner := pasted.(*fyne.Container).Objects[0].(*fyne.Container)
+	assert.Equal(t, "Settings", inner.Objects[0].(*widget.Label).Text)
+	assert.Equal(t, "screens/header.gui.json", other.Metadata()[inner][guidefs.ComponentKey])
+}
+
+func TestComponentDocumentsKeepMetadata(t *testing.T) {
+	d := DefaultContext()
+	in := `{"Type": "*fyne.Container", "Layout": "VBox", "Objects": [
+		{"Type": "Component", "Component": "screens/header.gui.json", "Overrides": {"title": {"Text": "Settings"}}}]}`
+	obj, err := DecodeObjectWithOptions(strings.NewReader(in), d, DecodeOptions{LoadComponent: loadTestComponent})
+	require.NoError(t, err)
+	header := obj.(*fyne.Container).Objects[0]
+	before := map[string]string{}
+	for k, v := range d.Metadata()[header] {
+		before[k] = v
+	}
+
+	docs, err := componentDocuments(obj, d)
+	require.NoError(t, err)
+	assert.Equal(t, before, d.Metadata()[header])
+	require.Contains(t, docs, "screens/header.gui.json")
+	doc := string(docs["screens/header.gui.json"])
+	assert.NotContains(t, doc, guidefs.ComponentKey)
+	assert.Contains(t, doc, "Settings")
+}
+
+func TestDecodeComponentRelativePath(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "screens"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "screens", "header.gui.json"), []byte(headerComponentJSON), 0o644))
+	main := `{"Type": "*fyne.Container", "Layout": "VBox", "Objects": [{"Type": "Component", "Component": "header"}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "screens", "main.gui.json"), []byte(main), 0o644))
+
+	d := DefaultContext()
+	in := `{"Type": "Component", "Component": "screens/main.gui.json"}`
+	obj, err := DecodeObjectWithOptions(strings.NewReader(in), d, DecodeOptions{Dir: dir})
+	require.NoError(t, err)
+	header := obj.(*fyne.Container).Objects[0].(*fyne.Container)
+	assert.Equal(t, "Header", header.Objects[0].(*widget.Label).Text)
+	assert.Equal(t, "header", d.Metadata()[header][guidefs.ComponentKey])
+}
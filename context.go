@@ -10,9 +10,38 @@ import (
 // Context defines a graphical builder context that contains metadata and theme information.
 type Context = guidefs.Context
 
+// Registry holds the widget types and icons that a context can use, see `NewRegistry` and `ContextWithRegistry`.
+type Registry = guidefs.Registry
+
+// History records the changes made by editors from `EditorFor` so that they can be undone and redone.
+type History = guidefs.History
+
+// Resource is a custom resource that can be registered by name in the map returned by `ResourcesOf`.
+type Resource = guidefs.Resource
+
+// HistoryContext is implemented by contexts that record the changes made by editors, such as the one from `DefaultContext`.
+// The changes made in contexts that do not implement it cannot be undone.
+type HistoryContext = guidefs.HistoryContext
+
+// HistoryOf returns the history of changes made in a context, or nil if it does not implement `HistoryContext`.
+func HistoryOf(d Context) *History {
+	return guidefs.HistoryOf(d)
+}
+
+// RegistryContext is implemented by contexts that resolve types using their own registry, see `ContextWithRegistry`.
+// Contexts that do not implement it use the default registry.
+type RegistryContext = guidefs.RegistryContext
+
+// ResourceContext is implemented by contexts that have custom resources, such as the one from `DefaultContext`.
+// Contexts that do not implement it have no custom resources, and only use the theme icons.
+type ResourceContext = guidefs.ResourceContext
+
 type context struct {
 	meta map[fyne.CanvasObject]map[string]string
 	attr map[fyne.CanvasObject][]string
+	res  map[string]Resource
+	reg  *Registry
+	hist *History
 	root fyne.CanvasObject
 }
 
@@ -22,9 +51,18 @@ func DefaultContext() Context {
 	return &context{
 		meta: make(map[fyne.CanvasObject]map[string]string),
 		attr: make(map[fyne.CanvasObject][]string),
+		res:  make(map[string]Resource),
 	}
 }
 
+// ContextWithRegistry returns a context like `DefaultContext` that resolves widget types using the given registry.
+// This allows builder sessions in the same process to offer different widgets.
+func ContextWithRegistry(r *Registry) Context {
+	c := DefaultContext().(*context)
+	c.reg = r
+	return c
+}
+
 func (c *context) Metadata() map[fyne.CanvasObject]map[string]string {
 	return c.meta
 }
@@ -37,17 +75,59 @@ func (c *context) Root() fyne.CanvasObject {
 	return c.root
 }
 
+func (c *context) Resources() map[string]Resource {
+	if c.res == nil {
+		c.res = make(map[string]Resource)
+	}
+	return c.res
+}
+
+// ResourcesOf returns the custom resources of a context, keyed by name, so that new ones can be registered.
+// It returns nil if the context does not implement `ResourceContext`.
+func ResourcesOf(d Context) map[string]Resource {
+	return guidefs.ResourcesOf(d)
+}
+
+func (c *context) Registry() *Registry {
+	return c.reg
+}
+
+func (c *context) History() *History {
+	if c.hist == nil {
+		c.hist = &History{}
+	}
+	return c.hist
+}
+
 func (c *context) Theme() fyne.Theme {
 	return theme.DefaultTheme()
 }
 
+// Undo reverts the most recent group of changes made by editors in the context.
+// It returns false if there was nothing to undo.
+func Undo(d Context) bool {
+	if h := guidefs.HistoryOf(d); h != nil {
+		return h.Undo(d)
+	}
+	return false
+}
+
+// Redo applies the most recently undone group of changes in the context again.
+// It returns false if there was nothing to redo.
+func Redo(d Context) bool {
+	if h := guidefs.HistoryOf(d); h != nil {
+		return h.Redo(d)
+	}
+	return false
+}
+
 // ContainerOf returns the parent of the given CanvasObject, in the specified Context.
 // The returned object will be in the tree descended from `c.Root()`, or nil.
 func ContainerOf(obj fyne.CanvasObject, c Context) fyne.CanvasObject {
-	return containerOf(obj, c.Root())
+	return containerOf(obj, c.Root(), c)
 }
 
-func containerOf(obj fyne.CanvasObject, root fyne.CanvasObject) fyne.CanvasObject {
+func containerOf(obj fyne.CanvasObject, root fyne.CanvasObject, d Context) fyne.CanvasObject {
 	switch c := root.(type) {
 	case *fyne.Container:
 		for _, w := range c.Objects {
@@ -55,21 +135,21 @@ func containerOf(obj fyne.CanvasObject, root fyne.CanvasObject) fyne.CanvasObjec
 				return root
 			}
 
-			parent := containerOf(obj, w)
+			parent := containerOf(obj, w, d)
 			if parent != nil {
 				return parent
 			}
 		}
 
 	case fyne.Widget:
-		drops := DropZonesForObject(root)
+		drops := DropZones(root, d)
 
 		for _, child := range drops {
 			if child == obj {
 				return root
 			}
 
-			parent := containerOf(obj, child)
+			parent := containerOf(obj, child, d)
 			if parent != nil {
 				return parent
 			}
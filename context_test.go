@@ -1,13 +1,21 @@
 package refyne
 
 import (
+	"bytes"
 	"image/color"
+	"sort"
 	"testing"
 
+	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/test"
 	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
 
+	"github.com/fyne-io/refyne/internal/guidefs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDefaultContext(t *testing.T) {
@@ -17,3 +25,203 @@ func TestDefaultContext(t *testing.T) {
 	o := canvas.NewRectangle(color.Black)
 	assert.Nil(t, d.Metadata()[o])
 }
+
+func TestUndoRedoEditor(t *testing.T) {
+	d := DefaultContext()
+	b := widget.NewButton("Button", func() {})
+	d.Metadata()[b] = map[string]string{}
+	changes := 0
+	items := EditorFor(b, d, func([]*widget.FormItem) {}, func() { changes++ })
+	text := items[0].Widget.(*widget.Entry)
+
+	text.SetText("Save")
+	d.Metadata()[b]["name"] = "save"
+	text.SetText("Save all")
+	assert.Equal(t, 2, changes)
+	assert.False(t, Redo(d))
+
+	require.True(t, Undo(d))
+	assert.Equal(t, "Save", b.Text)
+	assert.Equal(t, "", d.Metadata()[b]["name"])
+	require.True(t, Undo(d))
+	assert.Equal(t, "Button", b.Text)
+	assert.False(t, Undo(d))
+
+	require.True(t, Redo(d))
+	require.True(t, Redo(d))
+	assert.Equal(t, "Save all", b.Text)
+	assert.Equal(t, "save", d.Metadata()[b]["name"])
+}
+
+func TestUndoTabRename(t *testing.T) {
+	d := DefaultContext()
+	tabs := container.NewAppTabs(container.NewTabItem("One", widget.NewLabel("one")))
+	d.Metadata()[tabs] = map[string]string{}
+	items := EditorFor(tabs, d, func([]*widget.FormItem) {}, nil)
+	edit := items[1].Widget.(*fyne.Container).Objects[0].(*widget.Entry)
+
+	edit.SetText("First")
+	assert.Equal(t, "First", tabs.Items[0].Text)
+	require.True(t, HistoryOf(d).CanUndo())
+
+	require.True(t, Undo(d))
+	assert.Equal(t, "One", tabs.Items[0].Text)
+	require.True(t, Redo(d))
+	assert.Equal(t, "First", tabs.Items[0].Text)
+}
+
+func TestHistoryGroup(t *testing.T) {
+	d := DefaultContext()
+	b := widget.NewButton("Button", func() {})
+	items := EditorFor(b, d, func([]*widget.FormItem) {}, nil)
+	text := items[0].Widget.(*widget.Entry)
+
+	HistoryOf(d).Begin("rename")
+	text.SetText("One")
+	text.SetText("Two")
+	HistoryOf(d).End()
+	assert.True(t, HistoryOf(d).CanUndo())
+
+	require.True(t, Undo(d))
+	assert.Equal(t, "Button", b.Text)
+	assert.False(t, HistoryOf(d).CanUndo())
+	assert.True(t, HistoryOf(d).CanRedo())
+}
+
+func TestHistoryLimit(t *testing.T) {
+	d := DefaultContext()
+	b := widget.NewButton("Button", func() {})
+	items := EditorFor(b, d, func([]*widget.FormItem) {}, nil)
+	text := items[0].Widget.(*widget.Entry)
+
+	HistoryOf(d).SetLimit(2)
+	text.SetText("One")
+	text.SetText("Two")
+	text.SetText("Three")
+	require.True(t, Undo(d))
+	require.True(t, Undo(d))
+	assert.Equal(t, "One", b.Text)
+	assert.False(t, Undo(d))
+
+	require.True(t, Redo(d))
+	HistoryOf(d).Clear()
+	assert.False(t, HistoryOf(d).CanUndo())
+	assert.False(t, HistoryOf(d).CanRedo())
+}
+
+func TestTableColumnWidths(t *testing.T) {
+	d := DefaultContext()
+	decode := func(props map[string]interface{}) *widget.Table {
+		obj, err := DecodeMap(map[string]interface{}{"Type": "*widget.Table", "Struct": map[string]interface{}{},
+			"Properties": props}, d)
+		require.NoError(t, err)
+		return obj.(*widget.Table)
+	}
+	table := decode(map[string]interface{}{"columns": "3", "widths": "120, 80"})
+	template := columnWidths(t, decode(map[string]interface{}{"columns": "3"}))[1]
+	assert.Equal(t, []float32{120, 80}, columnWidths(t, table))
+
+	d.Metadata()[table]["widths"] = "50"
+	table.Length()
+	assert.Equal(t, []float32{120, 80}, columnWidths(t, table)) // not applied when the size is queried
+
+	d.Metadata()[table]["widths"] = "120, 80"
+	var widths *widget.Entry
+	for _, item := range EditorFor(table, d, func([]*widget.FormItem) {}, nil) {
+		if item.Text == "Widths" {
+			widths = item.Widget.(*widget.Entry)
+		}
+	}
+	require.NotNil(t, widths)
+	widths.SetText("100")
+	assert.Equal(t, []float32{100, template}, columnWidths(t, table))
+
+	require.True(t, Undo(d))
+	assert.Equal(t, []float32{120, 80}, columnWidths(t, table))
+}
+
+// columnWidths returns the widths of all but the last column of a table, found from the separators between them.
+func columnWidths(t *testing.T, table *widget.Table) []float32 {
+	w := test.NewTempWindow(t, table)
+	w.Resize(fyne.NewSize(600, 300))
+	table.Refresh()
+
+	var edges []float32
+	for _, o := range test.LaidOutObjects(table) {
+		if sep, ok := o.(*widget.Separator); ok && sep.Size().Height > sep.Size().Width {
+			edges = append(edges, sep.Position().X+sep.Size().Width/2)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i] < edges[j] })
+
+	pad := theme.Padding()
+	widths := make([]float32, len(edges))
+	start := -pad / 2
+	for i, edge := range edges {
+		widths[i] = edge - start - pad
+		start = edge
+	}
+	return widths
+}
+
+// minimalContext implements only the methods that every context must have.
+type minimalContext struct {
+	meta  map[fyne.CanvasObject]map[string]string
+	attrs map[fyne.CanvasObject][]string
+}
+
+func newMinimalContext() *minimalContext {
+	return &minimalContext{meta: make(map[fyne.CanvasObject]map[string]string), attrs: make(map[fyne.CanvasObject][]string)}
+}
+
+func (c *minimalContext) Metadata() map[fyne.CanvasObject]map[string]string { return c.meta }
+func (c *minimalContext) Attrs() map[fyne.CanvasObject][]string             { return c.attrs }
+func (c *minimalContext) Theme() fyne.Theme                                 { return theme.DefaultTheme() }
+func (c *minimalContext) Root() fyne.CanvasObject                           { return nil }
+
+func TestMinimalContext(t *testing.T) {
+	d := newMinimalContext()
+	assert.Nil(t, ResourcesOf(d))
+	assert.Equal(t, DefaultRegistry(), guidefs.RegistryOf(d))
+
+	b := widget.NewButtonWithIcon("Tap", theme.HomeIcon(), nil)
+	d.meta[b] = map[string]string{"name": "tap"}
+	buf := &bytes.Buffer{}
+	require.NoError(t, EncodeObject(b, d, buf))
+	assert.Contains(t, buf.String(), `"Icon": "HomeIcon"`)
+	obj, err := DecodeObject(bytes.NewReader(buf.Bytes()), d)
+	require.NoError(t, err)
+	assert.Equal(t, "Tap", obj.(*widget.Button).Text)
+
+	buf.Reset()
+	require.NoError(t, ExportGo(b, d, "main", buf))
+	assert.Contains(t, buf.String(), "theme.HomeIcon()")
+
+	assert.Nil(t, HistoryOf(d))
+	items := EditorFor(b, d, func([]*widget.FormItem) {}, nil)
+	items[0].Widget.(*widget.Entry).SetText("Go")
+	assert.Equal(t, "Go", b.Text)
+	assert.False(t, Undo(d))
+}
+
+// nilThemeContext is a minimal context that leaves the theme unset.
+type nilThemeContext struct {
+	*minimalContext
+}
+
+func (c nilThemeContext) Theme() fyne.Theme { return nil }
+
+func TestNilThemeContext(t *testing.T) {
+	d := nilThemeContext{newMinimalContext()}
+	buf := bytes.NewBufferString(`{
+  "Type": "*canvas.Rectangle",
+  "Struct": {"FillColor": null, "StrokeColor": null, "StrokeWidth": 0, "CornerRadius": 0},
+  "Properties": {"themeFillColor": "primary", "themeCornerRadius": "padding"}
+}`)
+	obj, err := DecodeObject(buf, d)
+	require.NoError(t, err)
+	rect := obj.(*canvas.Rectangle)
+	assert.Equal(t, theme.DefaultTheme().Color(theme.ColorNamePrimary, theme.VariantDark), rect.FillColor)
+	assert.Equal(t, theme.DefaultTheme().Size(theme.SizeNamePadding), rect.CornerRadius)
+	assert.Equal(t, theme.DefaultTheme(), guidefs.ThemeOf(d))
+}
@@ -15,7 +15,7 @@ import (
 func CreateNew(name string, d Context) fyne.CanvasObject {
 	guidefs.InitOnce()
 
-	if match := guidefs.Lookup(name); match != nil {
+	if match := guidefs.RegistryOf(d).Lookup(name); match != nil {
 		return match.Create(d)
 	}
 
@@ -24,14 +24,24 @@ func CreateNew(name string, d Context) fyne.CanvasObject {
 
 // EditorFor returns an array of FormItems for editing, taking the widget, properties, callback to refresh the form items,
 // and an optional callback that fires after changes to the widget.
+// Each change is recorded in the context `History`, if it implements `HistoryContext`, so that it can be undone.
 func EditorFor(o fyne.CanvasObject, d Context, refresh func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
 	guidefs.InitOnce()
 
-	_, clazz := getTypeOf(o)
+	_, clazz := getTypeOf(o, d)
 
 	if onchanged == nil {
 		onchanged = func() {}
 	}
+	if h := guidefs.HistoryOf(d); h != nil {
+		changed := onchanged
+		snap := guidefs.TakeSnapshot(o, d)
+		onchanged = func() {
+			h.Record(snap.Diff(d))
+			snap = guidefs.TakeSnapshot(o, d)
+			changed()
+		}
+	}
 
 	appendManualItems := func(items []*widget.FormItem) []*widget.FormItem {
 		parent := ContainerOf(o, d)
@@ -51,7 +61,7 @@ func EditorFor(o fyne.CanvasObject, d Context, refresh func([]*widget.FormItem),
 	}
 
 	var items []*widget.FormItem
-	if match := guidefs.Lookup(clazz); match != nil {
+	if match := guidefs.RegistryOf(d).Lookup(clazz); match != nil {
 		items = match.Edit(o, d, func(items []*widget.FormItem) {
 			items = appendManualItems(items)
 
@@ -67,17 +77,17 @@ func EditorFor(o fyne.CanvasObject, d Context, refresh func([]*widget.FormItem),
 func GoStringFor(o fyne.CanvasObject, d Context, defs map[string]string) string {
 	guidefs.InitOnce()
 
-	name, _ := getTypeOf(o)
+	name, _ := getTypeOf(o, d)
 
-	if match := guidefs.Lookup(name); match != nil {
+	if match := guidefs.RegistryOf(d).Lookup(name); match != nil {
 		return match.Gostring(o, d, defs)
 	}
 
 	return ""
 }
 
-func getTypeOf(o fyne.CanvasObject) (string, string) {
-	class := guidefs.TypeName(o)
+func getTypeOf(o fyne.CanvasObject, d Context) (string, string) {
+	class := guidefs.RegistryOf(d).TypeName(o)
 	name := NameOf(o)
 
 	return name, class
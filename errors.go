@@ -0,0 +1,88 @@
+package refyne
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity indicates how serious a problem found while decoding is.
+type Severity int
+
+const (
+	// SeverityWarning is used when some data was ignored but the object could still be created.
+	SeverityWarning Severity = iota
+	// SeverityError is used when an object could not be decoded and was left out of the tree.
+	SeverityError
+)
+
+// String returns a lower case name for the severity level.
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+
+	return "warning"
+}
+
+// DecodeIssue describes a single problem found in the JSON data.
+type DecodeIssue struct {
+	// Path is the location of the problem in the JSON document, i.e. "Objects[2].Struct.Items[1].Content".
+	// The root object has an empty path.
+	Path     string
+	Severity Severity
+	// Type is the type name of the object being decoded when the problem was found, if known.
+	Type    string
+	Message string
+}
+
+// String returns a single line summary of the issue.
+func (i DecodeIssue) String() string {
+	path := i.Path
+	if path == "" {
+		path = "(root)"
+	}
+	if i.Type == "" {
+		return fmt.Sprintf("%s: %s: %s", i.Severity, path, i.Message)
+	}
+
+	return fmt.Sprintf("%s: %s (%s): %s", i.Severity, path, i.Type, i.Message)
+}
+
+// DecodeError is returned from `DecodeObject` and `DecodeMap` when the data contained problems.
+// The object tree returned alongside it contains everything that could be decoded.
+type DecodeError struct {
+	Issues []DecodeIssue
+}
+
+// Error returns a summary of all the issues found.
+func (e *DecodeError) Error() string {
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		lines[i] = issue.String()
+	}
+
+	return fmt.Sprintf("%d problem(s) decoding object tree:\n%s", len(e.Issues), strings.Join(lines, "\n"))
+}
+
+// HasErrors returns true if any of the issues were errors rather than warnings.
+func (e *DecodeError) HasErrors() bool {
+	for _, issue := range e.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+func joinPath(path, elem string) string {
+	if path == "" {
+		return elem
+	}
+
+	return path + "." + elem
+}
@@ -3,10 +3,16 @@ package refyne
 import (
 	"fmt"
 	"go/format"
+	"go/scanner"
+	"go/token"
 	"io"
+	"io/fs"
+	"path"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"fyne.io/fyne/v2/container"
 	"github.com/fyne-io/refyne/internal/guidefs"
@@ -15,22 +21,46 @@ import (
 	"fyne.io/fyne/v2"
 )
 
+// ExportOptions configures the Go code written by `ExportGoWithOptions`.
+// Any names that are not set are derived from the component name, as they are for `ExportGo`.
+type ExportOptions struct {
+	// Package is the package clause of the generated file, "main" if not set.
+	Package string
+	// TypeName is the name of the generated GUI struct.
+	TypeName string
+	// Constructor is the name of the function that returns a new GUI struct.
+	Constructor string
+	// OmitWindow leaves the `win fyne.Window` field out of the GUI struct.
+	// Any callbacks that refer to `g.win` will then need it to be declared elsewhere.
+	OmitWindow bool
+	// Header replaces the comment at the top of the file, for example to add a build constraint or licence.
+	Header string
+}
+
 // ExportGo generates a full Go package for the given object and writes it to the provided file handle
 func ExportGo(obj fyne.CanvasObject, d Context, name string, w io.Writer) error {
+	return ExportGoWithOptions(obj, d, name, w, ExportOptions{})
+}
+
+// ExportGoWithOptions generates Go code for the given object, like `ExportGo`, using the package,
+// type names and header set in the options.
+func ExportGoWithOptions(obj fyne.CanvasObject, d Context, name string, w io.Writer, opts ExportOptions) error {
 	guidefs.InitOnce()
 
 	tools.VarNames.Reset()
 
 	packagesList := packagesRequired(obj, d)
 
-	// Really this needs to be a full dependency analysis but for now a simple sort of widgets before containers may work
 	varListWidgets, varListContainers := varsRequired(obj, d)
 	sort.Strings(varListWidgets)
 	sort.Strings(varListContainers)
 
-	code := exportCode(packagesList, append(varListWidgets, varListContainers...), obj, d, name)
+	code, err := exportCode(packagesList, append(varListWidgets, varListContainers...), obj, d, name, opts)
+	if err != nil {
+		return err
+	}
 
-	_, err := w.Write([]byte(code))
+	_, err = w.Write([]byte(code))
 	return err
 }
 
@@ -41,12 +71,14 @@ func ExportGoPreview(obj fyne.CanvasObject, d Context, w io.Writer) error {
 	packagesList := packagesRequired(obj, d)
 	packagesList = append(packagesList, "app")
 
-	// Really this needs to be a full dependency analysis but for now a simple sort of widgets before containers may work
 	varListWidgets, varListContainers := varsRequired(obj, d)
 	sort.Strings(varListWidgets)
 	sort.Strings(varListContainers)
 
-	code := exportCode(packagesList, append(varListWidgets, varListContainers...), obj, d, "main")
+	code, err := exportCode(packagesList, append(varListWidgets, varListContainers...), obj, d, "main", ExportOptions{})
+	if err != nil {
+		return err
+	}
 
 	code += `
 func main() {
@@ -58,7 +90,7 @@ func main() {
 	w.ShowAndRun()
 }
 `
-	_, err := w.Write([]byte(code))
+	_, err = w.Write([]byte(code))
 
 	return err
 }
@@ -91,24 +123,313 @@ func countContainers(obj fyne.CanvasObject) int {
 	return r
 }
 
-func stringMapToSlice(m map[string]string, fn func(a, b string) bool) []string {
-	keys := make([]string, len(m))
-	n := 0
-	for key := range m {
-		keys[n] = key
-		n++
+// orderDefinitions returns the names of the setup lines ordered so that each variable is assigned before it is used.
+// Where more than one line could come next the first according to `less` is chosen, so output is stable.
+// If the definitions depend on each other in a cycle an error describing the cycle is returned.
+func orderDefinitions(lines, defs map[string]string, generated map[string]bool, tree map[string][]string,
+	less func(a, b string) bool) ([]string, error) {
+	needs := make(map[string]map[string]bool, len(lines))
+	for name := range lines {
+		needs[name] = make(map[string]bool)
+		for _, dep := range append(definitionReferences(defs[name], lines, generated), tree[name]...) {
+			if _, ok := lines[dep]; ok && dep != name {
+				needs[name][dep] = true
+			}
+		}
+	}
+
+	users := make(map[string][]string)
+	pending := make(map[string]int, len(needs))
+	var ready []string
+	for name, deps := range needs {
+		for dep := range deps {
+			users[dep] = append(users[dep], name)
+		}
+		pending[name] = len(deps)
+		if len(deps) == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	order := make([]string, 0, len(lines))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool {
+			return less(ready[i], ready[j])
+		})
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+
+		for _, user := range users[name] {
+			pending[user]--
+			if pending[user] == 0 {
+				ready = append(ready, user)
+			}
+		}
+	}
+
+	if len(order) < len(lines) {
+		return nil, fmt.Errorf("dependency cycle in GUI definitions: %s", strings.Join(findCycle(needs, pending), " -> "))
+	}
+	return order, nil
+}
+
+// definitionReferences returns the variables that are used by a line of generated code.
+// These are either fields of the GUI struct, such as `g.name`, or local variables for generated names.
+func definitionReferences(code string, lines map[string]string, generated map[string]bool) []string {
+	src := []byte(code)
+	fset := token.NewFileSet()
+	var s scanner.Scanner
+	s.Init(fset.AddFile("", fset.Base(), len(src)), src, nil, 0)
+
+	var refs []string
+	prev, prevLit, field := token.ILLEGAL, "", false
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+
+		if tok == token.IDENT {
+			_, defined := lines[lit]
+			if field && defined && !generated[lit] {
+				refs = append(refs, lit)
+			} else if prev != token.PERIOD && generated[lit] {
+				refs = append(refs, lit)
+			}
+		}
+		field = tok == token.PERIOD && prev == token.IDENT && prevLit == "g"
+		prev, prevLit = tok, lit
+	}
+	return refs
+}
+
+// embeddedResources returns the declarations that load the custom resources used by the code from embedded files.
+// An error is returned if the path of a used resource cannot be embedded.
+func embeddedResources(code string, d Context) ([]string, error) {
+	embedded := make(map[string]bool)
+	files := make(map[string]string)
+	for name, v := range guidefs.ResourceVariables(d) {
+		if r := guidefs.ResourcesOf(d)[name]; r.Embed != "" {
+			embedded[v] = true
+			files[v] = r.Embed
+		}
+	}
+	if len(embedded) == 0 {
+		return nil, nil
+	}
+
+	used := make(map[string]bool)
+	for _, v := range definitionReferences(code, nil, embedded) {
+		used[v] = true
+	}
+	vars := make([]string, 0, len(used))
+	for v := range used {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+
+	decls := make([]string, len(vars))
+	for i, v := range vars {
+		pattern, err := embedPattern(files[v])
+		if err != nil {
+			return nil, err
+		}
+		decls[i] = fmt.Sprintf("//go:embed %s\nvar %sData []byte\n\nvar %s = fyne.NewStaticResource(%q, %sData)",
+			pattern, v, v, path.Base(files[v]), v)
+	}
+	return decls, nil
+}
+
+// embedPattern returns the path of a file in the form used by a `//go:embed` directive, quoted if it contains spaces.
+// Paths must be inside the package directory, so they cannot start with "/" or contain "." or ".." elements,
+// and they cannot contain the characters that would make them a pattern for other files.
+func embedPattern(file string) (string, error) {
+	if !fs.ValidPath(file) || file == "." || strings.ContainsAny(file, "\\*?[") {
+		return "", fmt.Errorf("resource path %q cannot be embedded, it must be a file path inside the package", file)
+	}
+
+	if strings.IndexFunc(file, unicode.IsSpace) >= 0 || strings.ContainsAny(file, "\"`") {
+		return strconv.Quote(file), nil
+	}
+	return file, nil
+}
+
+// findCycle returns a path around one of the cycles left in the dependency graph, starting and ending at the same name.
+func findCycle(needs map[string]map[string]bool, pending map[string]int) []string {
+	var remaining []string
+	for name, count := range pending {
+		if count > 0 {
+			remaining = append(remaining, name)
+		}
+	}
+	sort.Strings(remaining)
+
+	seen := make(map[string]int)
+	var path []string
+	name := remaining[0]
+	for {
+		if i, ok := seen[name]; ok {
+			return append(path[i:], name)
+		}
+		seen[name] = len(path)
+		path = append(path, name)
+
+		next := ""
+		for dep := range needs[name] {
+			if pending[dep] > 0 && (next == "" || dep < next) {
+				next = dep
+			}
+		}
+		name = next
+	}
+}
+
+// treeDependencies maps each named object to the nearest named objects inside it, as they must be created first.
+func treeDependencies(obj fyne.CanvasObject, d Context) map[string][]string {
+	deps := make(map[string][]string)
+
+	var walk func(fyne.CanvasObject, string)
+	walk = func(o fyne.CanvasObject, parent string) {
+		if o == nil || guidefs.IsComponent(o, d) {
+			return
+		}
+		if name := d.Metadata()[o]["name"]; name != "" {
+			if parent != "" {
+				deps[parent] = append(deps[parent], name)
+			}
+			parent = name
+		}
+
+		for _, child := range childObjects(o, d) {
+			walk(child, parent)
+		}
+	}
+	walk(obj, "")
+	return deps
+}
+
+type bindingField struct {
+	name, kind string
+}
+
+// bindingsRequired returns the binding fields that bound widgets in the tree use, sorted by name.
+// It is an error for a field to be used by bindings of different types, or to have the same name as a widget.
+func bindingsRequired(obj fyne.CanvasObject, d Context, vars []string) ([]bindingField, error) {
+	widgets := make(map[string]bool)
+	for _, v := range vars {
+		widgets[strings.Split(v, " ")[0]] = true
+	}
+
+	kinds := make(map[string]string)
+	var walk func(fyne.CanvasObject) error
+	walk = func(o fyne.CanvasObject) error {
+		if o == nil || guidefs.IsComponent(o, d) {
+			return nil
+		}
+		if name := guidefs.Binding(o, d); name != "" {
+			kind := guidefs.BindingTypes[guidefs.RegistryOf(d).TypeName(o)]
+			if widgets[name] || name == "win" {
+				return fmt.Errorf("binding %s has the same name as another field", name)
+			} else if prev, ok := kinds[name]; ok && prev != kind {
+				return fmt.Errorf("binding %s is used as both %s and %s", name, prev, kind)
+			}
+			kinds[name] = kind
+		}
+
+		for _, child := range childObjects(o, d) {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(obj); err != nil {
+		return nil, err
+	}
+
+	fields := make([]bindingField, 0, len(kinds))
+	for name, kind := range kinds {
+		fields = append(fields, bindingField{name: name, kind: kind})
 	}
-	sort.Slice(keys, func(i, j int) bool {
-		return fn(keys[i], keys[j])
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].name < fields[j].name
 	})
-	r := make([]string, len(keys))
-	for i, key := range keys {
-		r[i] = m[key]
+	return fields, nil
+}
+
+func appendMissing(list []string, item string) []string {
+	for _, exists := range list {
+		if exists == item {
+			return list
+		}
 	}
-	return r
+	return append(list, item)
+}
+
+// childObjects returns the objects directly inside a container, or nil for other objects.
+// The objects of a component are created by the GUI exported for it, so they are not included.
+func childObjects(o fyne.CanvasObject, d Context) []fyne.CanvasObject {
+	if guidefs.IsComponent(o, d) {
+		return nil
+	}
+	if c, ok := o.(*fyne.Container); ok {
+		return c.Objects
+	}
+	reg := guidefs.RegistryOf(d)
+	if info := reg.Lookup(reg.TypeName(o)); info != nil && info.IsContainer() {
+		return info.Children(o)
+	}
+	return nil
 }
 
-func exportCode(pkgs, vars []string, obj fyne.CanvasObject, d Context, name string) string {
+// exportNames returns the GUI struct and constructor names for a component, using the options where set.
+// The defaults are derived from the name, so an error is returned if it is empty and an option is not set.
+func exportNames(name string, opts ExportOptions) (typeName, constructor string, err error) {
+	typeName, constructor = opts.TypeName, opts.Constructor
+	if typeName != "" && constructor != "" {
+		return typeName, constructor, nil
+	}
+	if name == "" {
+		return "", "", fmt.Errorf("a name is required unless the type name and constructor are set")
+	}
+
+	defType, defConstructor := "gui", "newGUI"
+	if name != "main" {
+		defType = name + "Gui"
+		defConstructor = "new" + strings.ToUpper(name[:1]) + name[1:] + "GUI"
+	}
+	if typeName == "" {
+		typeName = defType
+	}
+	if constructor == "" {
+		constructor = defConstructor
+	}
+	return typeName, constructor, nil
+}
+
+func exportPackage(opts ExportOptions) string {
+	if opts.Package == "" {
+		return "main"
+	}
+	return opts.Package
+}
+
+func exportCode(pkgs, vars []string, obj fyne.CanvasObject, d Context, name string, opts ExportOptions) (string, error) {
+	bindings, err := bindingsRequired(obj, d, vars)
+	if err != nil {
+		return "", err
+	}
+	if len(bindings) > 0 {
+		pkgs = appendMissing(pkgs, "fyne.io/fyne/v2/data/binding")
+	}
+	bindingVars := make([]string, len(bindings))
+	bindingInits := make([]string, len(bindings))
+	for i, b := range bindings {
+		bindingVars[i] = b.name + " binding." + b.kind
+		bindingInits[i] = b.name + ": binding.New" + b.kind + "()"
+	}
+
 	for i := 0; i < len(pkgs); i++ {
 		if pkgs[i] == "xWidget" {
 			pkgs[i] = `xWidget	"fyne.io/x/fyne/widget"`
@@ -137,46 +458,44 @@ func exportCode(pkgs, vars []string, obj fyne.CanvasObject, d Context, name stri
 
 	defs := make(map[string]string)
 
-	_, clazz := getTypeOf(obj)
+	_, clazz := getTypeOf(obj, d)
 	main := guidefs.GoString(clazz, obj, d, defs)
-	setupBeforeMap := make(map[string]string)
-	setupAfterMap := make(map[string]string)
 
-	genids := make(map[string]bool)
+	generated := make(map[string]bool)
+	lines := make(map[string]string)
 	for _, p := range d.Metadata() {
 		if g, ok := p["name-is-generated"]; ok && g == "1" {
-			genids[name] = true
 			name := p["name"]
-			if deps[name] > 0 {
-				setupAfterMap[name] = name + " := " + defs[name]
-			} else {
-				setupBeforeMap[name] = name + " := " + defs[name]
-			}
+			generated[name] = true
+			lines[name] = name + " := " + defs[name]
 		}
 	}
 
 	for _, key := range vars {
 		name := strings.Split(key, " ")[0]
-		if genids[name] {
+		if generated[name] {
 			continue
 		}
-		if deps[name] > 0 {
-			setupAfterMap[name] = "g." + name + " = " + defs[name]
-		} else {
-			setupBeforeMap[name] = "g." + name + " = " + defs[name]
-		}
+		lines[name] = "g." + name + " = " + defs[name]
 	}
 
-	setupBefore := stringMapToSlice(setupBeforeMap, func(a, b string) bool {
-		return a < b
-	})
-
-	setupAfter := stringMapToSlice(setupAfterMap, func(a, b string) bool {
+	// widgets before containers, and then by name, where the dependencies allow
+	order, err := orderDefinitions(lines, defs, generated, treeDependencies(obj, d), func(a, b string) bool {
+		if (deps[a] > 0) != (deps[b] > 0) {
+			return deps[a] == 0
+		}
 		if deps[a] == deps[b] {
 			return a < b
 		}
 		return deps[a] < deps[b]
 	})
+	if err != nil {
+		return "", err
+	}
+	setup := make([]string, len(order))
+	for i, name := range order {
+		setup[i] = lines[name]
+	}
 
 	attrs := []string{}
 	for obj, props := range d.Metadata() {
@@ -205,13 +524,20 @@ func exportCode(pkgs, vars []string, obj fyne.CanvasObject, d Context, name stri
 		d.Metadata()[obj] = props
 	}
 
-	guiName := "gui"
-	guiNameUpper := ""
+	resources, err := embeddedResources(strings.Join(append(append(setup, attrs...), main), "\n"), d)
+	if err != nil {
+		return "", err
+	}
+	if len(resources) > 0 {
+		pkgs = append(pkgs, `	_ "embed"`)
+	}
+
+	guiName, constructor, err := exportNames(name, opts)
+	if err != nil {
+		return "", err
+	}
 	layoutHelper := ""
-	if name != "main" {
-		guiName = name + "Gui"
-		guiNameUpper = strings.ToUpper(string([]byte{name[0]})) + name[1:]
-	} else {
+	if name == "main" {
 		layoutHelper = `type wrappedLayout struct {
 	layout  func([]fyne.CanvasObject, fyne.Size)
 	minSize func([]fyne.CanvasObject) fyne.Size
@@ -230,31 +556,45 @@ func wrapLayout(l func([]fyne.CanvasObject, fyne.Size), m func([]fyne.CanvasObje
 }`
 	}
 
+	header := strings.TrimRight(opts.Header, "\n")
+	if header == "" {
+		header = "// auto-generated\n// Code generated by GUI builder."
+	}
+
 	data := struct {
+		Header       string
+		Package      string
 		Pkgs         []string
 		LayoutHelper string
+		Resources    []string
 		GuiName      string
-		GuiNameUpper string
+		Constructor  string
+		Window       bool
 		Vars         []string
+		Bindings     []string
+		BindingInits []string
 		Attrs        []string
-		SetupBefore  []string
-		SetupAfter   []string
+		Setup        []string
 		Main         string
 	}{
+		Header:       header,
+		Package:      exportPackage(opts),
 		Pkgs:         pkgs,
 		LayoutHelper: layoutHelper,
+		Resources:    resources,
 		GuiName:      guiName,
-		GuiNameUpper: guiNameUpper,
+		Constructor:  constructor,
+		Window:       !opts.OmitWindow,
 		Vars:         vars,
+		Bindings:     bindingVars,
+		BindingInits: bindingInits,
 		Attrs:        attrs,
-		SetupBefore:  setupBefore,
-		SetupAfter:   setupAfter,
+		Setup:        setup,
 		Main:         main,
 	}
-	code, err := tools.RenderCode(`// auto-generated
-// Code generated by GUI builder.
+	code, err := tools.RenderCode(`{{.Header}}
 
-package main
+package {{.Package}}
 
 import (
 	"fyne.io/fyne/v2"
@@ -264,24 +604,37 @@ import (
 )
 
 {{.LayoutHelper}}
-
+{{ range .Resources }}
+{{.}}
+{{ end }}
 type {{.GuiName}} struct {
+{{- if .Window }}
 	win fyne.Window
-
+{{ end }}
 {{ range .Vars }}
 	{{.}}
 {{- end }}
+{{- if .Bindings }}
+{{ range .Bindings }}
+	{{.}}
+{{- end }}
+{{- end }}
 }
 
-func new{{.GuiNameUpper}}GUI() *{{.GuiName}} {
+func {{.Constructor}}() *{{.GuiName}} {
+{{- if .BindingInits }}
+	return &{{.GuiName}}{
+{{- range .BindingInits }}
+		{{.}},
+{{- end }}
+	}
+{{- else }}
 	return &{{.GuiName}}{}
+{{- end }}
 }
 
 func (g *{{.GuiName}}) makeUI() fyne.CanvasObject {
-	{{ range .SetupBefore -}}
-	{{.}}
-	{{ end -}}
-	{{ range .SetupAfter -}}
+	{{ range .Setup -}}
 	{{.}}
 	{{ end -}}
 
@@ -293,29 +646,34 @@ func (g *{{.GuiName}}) makeUI() fyne.CanvasObject {
 }`, data)
 	if err != nil {
 		fyne.LogError("Failed to generate GUI code", err)
-		return code
+		return code, nil
 	}
 
 	formatted, err := format.Source([]byte(code))
 	if err != nil {
 		fyne.LogError("Failed to format GUI code", err)
-		return code
+		return code, nil
 	}
-	return string(formatted)
+	return string(formatted), nil
 }
 
 func packagesRequired(obj fyne.CanvasObject, d Context) []string {
+	if guidefs.IsComponent(obj, d) {
+		return []string{}
+	}
+
 	ret := []string{"container"}
 	var objs []fyne.CanvasObject
 	if c, ok := obj.(*fyne.Container); ok {
 		objs = c.Objects
-		layout, ok := d.Metadata()[c]["layout"]
-		if ok && (layout == "Form" || layout == "CustomPadded" || layout == "GridWrap") {
-			ret = append(ret, "layout")
+		if info, ok := guidefs.RegistryOf(d).Layout(d.Metadata()[c]["layout"]); ok && info.Packages != nil {
+			for _, p := range info.Packages(c, d) {
+				ret = appendMissing(ret, p)
+			}
 		}
 	} else {
 		class := reflect.TypeOf(obj).String()
-		info := guidefs.Lookup(class)
+		info := guidefs.RegistryOf(d).Lookup(class)
 
 		if info != nil && info.IsContainer() {
 			ret = packagesRequiredForWidget(obj, d)
@@ -343,8 +701,8 @@ func packagesRequired(obj fyne.CanvasObject, d Context) []string {
 }
 
 func packagesRequiredForWidget(w fyne.CanvasObject, d Context) []string {
-	_, name := getTypeOf(w)
-	if pkgs := guidefs.Lookup(name).Packages; pkgs != nil {
+	_, name := getTypeOf(w, d)
+	if pkgs := guidefs.RegistryOf(d).Lookup(name).Packages; pkgs != nil {
 		return pkgs(w, d)
 	}
 
@@ -356,6 +714,9 @@ func packagesRequiredForWidget(w fyne.CanvasObject, d Context) []string {
 }
 
 func varsRequired(obj fyne.CanvasObject, d Context) (widgets, containers []string) {
+	if guidefs.IsComponent(obj, d) {
+		return
+	}
 	name := d.Metadata()[obj]["name"]
 
 	if c, ok := obj.(*fyne.Container); ok {
@@ -374,7 +735,7 @@ func varsRequired(obj fyne.CanvasObject, d Context) (widgets, containers []strin
 		}
 	} else {
 		class := reflect.TypeOf(obj).String()
-		info := guidefs.Lookup(class)
+		info := guidefs.RegistryOf(d).Lookup(class)
 
 		if info != nil && info.IsContainer() {
 			for _, child := range info.Children(obj) {
@@ -390,7 +751,7 @@ func varsRequired(obj fyne.CanvasObject, d Context) (widgets, containers []strin
 		}
 
 		if name != "" {
-			_, class := getTypeOf(obj)
+			_, class := getTypeOf(obj, d)
 			widgets = append(widgets, name+" "+class)
 		}
 	}
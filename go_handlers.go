@@ -0,0 +1,282 @@
+package refyne
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+)
+
+type handler struct {
+	name, event string
+	fn          reflect.Type
+}
+
+// ExportGoHandlers writes the user-editable companion to the code generated by `ExportGoWithOptions`.
+// It contains a method stub on the GUI struct for each callback that the actions in the context metadata refer to,
+// such as "g.onLogin", with the signature that the widget event requires.
+// The current content of the companion file, if there is one, should be passed as `existing`.
+// That content is written back unchanged apart from the imports that are needed,
+// followed by stubs for the methods it does not already declare.
+func ExportGoHandlers(obj fyne.CanvasObject, d Context, name string, existing []byte, w io.Writer, opts ExportOptions) error {
+	guidefs.InitOnce()
+
+	typeName, _, err := exportNames(name, opts)
+	if err != nil {
+		return err
+	}
+	handlers, err := handlersRequired(obj, d)
+	if err != nil {
+		return err
+	}
+
+	src := existing
+	if len(bytes.TrimSpace(src)) == 0 {
+		src = []byte(handlersHeader(opts))
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	declared := make(map[string]bool)
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil && receiverName(fn.Recv) == typeName {
+			declared[fn.Name.Name] = true
+		}
+	}
+
+	var stubs strings.Builder
+	imports := make(map[string]bool)
+	for _, h := range handlers {
+		if declared[h.name] {
+			continue
+		}
+
+		collectImports(h.fn, imports)
+		fmt.Fprintf(&stubs, "\n// %s handles the %s event.\nfunc (g *%s) %s%s {\n", h.name, h.event, typeName, h.name,
+			stubSignature(h.fn))
+		if h.fn.NumOut() > 0 {
+			stubs.WriteString("\treturn\n")
+		}
+		stubs.WriteString("}\n")
+	}
+	if stubs.Len() == 0 {
+		_, err = w.Write(src)
+		return err
+	}
+
+	for _, spec := range file.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil {
+			delete(imports, path)
+		}
+	}
+	code := addImports(src, fset, file, imports)
+	code = strings.TrimRight(code, "\n") + "\n" + stubs.String()
+
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		fyne.LogError("Failed to format GUI handlers", err)
+		formatted = []byte(code)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+// handlersRequired returns the methods that actions in the object tree refer to, sorted by name.
+// Actions that refer to widgets or bindings on the GUI struct, or to code outside it, are ignored.
+func handlersRequired(obj fyne.CanvasObject, d Context) ([]handler, error) {
+	fields := map[string]bool{"win": true}
+	widgets, containers := varsRequired(obj, d)
+	vars := append(widgets, containers...)
+	for _, v := range vars {
+		fields[strings.Split(v, " ")[0]] = true
+	}
+	bindings, err := bindingsRequired(obj, d, vars)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bindings {
+		fields[b.name] = true
+	}
+
+	found := make(map[string]handler)
+	var walk func(fyne.CanvasObject) error
+	walk = func(o fyne.CanvasObject) error {
+		if o == nil || guidefs.IsComponent(o, d) {
+			return nil
+		}
+
+		v := reflect.ValueOf(o)
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		for event, action := range d.Metadata()[o] {
+			if len(event) <= 2 || event[0:2] != "On" || v.Kind() != reflect.Struct {
+				continue
+			}
+			name := strings.TrimPrefix(action, "g.")
+			if name == action || !token.IsIdentifier(name) || fields[name] {
+				continue
+			}
+			f := v.FieldByName(event)
+			if !f.IsValid() || f.Kind() != reflect.Func {
+				continue
+			}
+
+			if prev, ok := found[name]; ok && prev.fn != f.Type() {
+				return fmt.Errorf("handler %s is used for %s and %s, which have different signatures",
+					name, prev.event, event)
+			}
+			found[name] = handler{name: name, event: event, fn: f.Type()}
+		}
+
+		for _, child := range childObjects(o, d) {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(obj); err != nil {
+		return nil, err
+	}
+
+	handlers := make([]handler, 0, len(found))
+	for _, h := range found {
+		handlers = append(handlers, h)
+	}
+	sort.Slice(handlers, func(i, j int) bool {
+		return handlers[i].name < handlers[j].name
+	})
+	return handlers, nil
+}
+
+// handlersHeader returns the start of a new handlers file, keeping any build constraint from the generated file.
+func handlersHeader(opts ExportOptions) string {
+	header := ""
+	for _, line := range strings.Split(opts.Header, "\n") {
+		if strings.HasPrefix(line, "//go:build") {
+			header += line + "\n\n"
+		}
+	}
+	return header + "package " + exportPackage(opts) + "\n"
+}
+
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+
+	t := recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// stubSignature returns the parameters and results of a method with the given function type.
+func stubSignature(fn reflect.Type) string {
+	list := func(prefix string, count int, typ func(int) reflect.Type) string {
+		items := make([]string, count)
+		for i := range items {
+			name := prefix
+			if count > 1 {
+				name += strconv.Itoa(i + 1)
+			}
+			items[i] = name + " " + typ(i).String()
+		}
+		return strings.Join(items, ", ")
+	}
+
+	sig := "(" + list("value", fn.NumIn(), fn.In) + ")"
+	if fn.IsVariadic() {
+		last := fn.In(fn.NumIn() - 1)
+		sig = strings.Replace(sig, " "+last.String()+")", " ..."+last.Elem().String()+")", 1)
+	}
+	if fn.NumOut() > 0 {
+		sig += " (" + list("result", fn.NumOut(), fn.Out) + ")"
+	}
+	return sig
+}
+
+// collectImports adds the packages that are needed to refer to a type.
+func collectImports(t reflect.Type, imports map[string]bool) {
+	if t.Name() != "" {
+		if t.PkgPath() != "" {
+			imports[t.PkgPath()] = true
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Ptr, reflect.Slice:
+		collectImports(t.Elem(), imports)
+	case reflect.Map:
+		collectImports(t.Key(), imports)
+		collectImports(t.Elem(), imports)
+	case reflect.Func:
+		for i := 0; i < t.NumIn(); i++ {
+			collectImports(t.In(i), imports)
+		}
+		for i := 0; i < t.NumOut(); i++ {
+			collectImports(t.Out(i), imports)
+		}
+	}
+}
+
+// addImports inserts import specs for the given paths into the source, without changing anything else.
+func addImports(src []byte, fset *token.FileSet, file *ast.File, imports map[string]bool) string {
+	if len(imports) == 0 {
+		return string(src)
+	}
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, strconv.Quote(path))
+	}
+	sort.Strings(paths)
+
+	var last *ast.GenDecl
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+
+	code := string(src)
+	if last != nil && last.Rparen.IsValid() {
+		at := fset.Position(last.Rparen).Offset
+		return code[:at] + "\t" + strings.Join(paths, "\n\t") + "\n" + code[at:]
+	}
+
+	if last != nil { // a single import without brackets is replaced by a group
+		start, end := fset.Position(last.Specs[0].Pos()).Offset, fset.Position(last.End()).Offset
+		paths = append([]string{code[start:end]}, paths...)
+		return code[:fset.Position(last.Pos()).Offset] + importDecl(paths) + code[end:]
+	}
+
+	at := fset.Position(file.Name.End()).Offset
+	return code[:at] + "\n\n" + importDecl(paths) + code[at:]
+}
+
+func importDecl(specs []string) string {
+	if len(specs) == 1 {
+		return "import " + specs[0]
+	}
+	return "import (\n\t" + strings.Join(specs, "\n\t") + "\n)"
+}
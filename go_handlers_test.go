@@ -0,0 +1,104 @@
+package refyne
+
+import (
+	"bytes"
+	"testing"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func handlersTestTree(ctx Context) *container.Split {
+	b := widget.NewButton("Login", nil)
+	ctx.Metadata()[b] = map[string]string{"OnTapped": "g.onLogin"}
+	e := widget.NewEntry()
+	ctx.Metadata()[e] = map[string]string{"name": "user", "OnChanged": "g.onUser", "OnSubmitted": "g.win.Close"}
+	l := widget.NewLabel("Status")
+	ctx.Metadata()[l] = map[string]string{"name": "status"}
+	t := widget.NewTable(nil, nil, nil)
+	ctx.Metadata()[t] = map[string]string{"OnSelected": "g.onCell", "OnUnselected": "g.status.Hide"}
+
+	return container.NewHSplit(container.NewVBox(b, e, l), t)
+}
+
+func TestExportGoHandlers(t *testing.T) {
+	ctx := DefaultContext()
+	obj := handlersTestTree(ctx)
+
+	buf := &bytes.Buffer{}
+	opts := ExportOptions{Package: "ui", Header: "//go:build ui\n\n// Code generated by GUI builder. DO NOT EDIT."}
+	require.NoError(t, ExportGoHandlers(obj, ctx, "login", nil, buf, opts))
+	assert.Equal(t, `//go:build ui
+
+package ui
+
+import "fyne.io/fyne/v2/widget"
+
+// onCell handles the OnSelected event.
+func (g *loginGui) onCell(value widget.TableCellID) {
+}
+
+// onLogin handles the OnTapped event.
+func (g *loginGui) onLogin() {
+}
+
+// onUser handles the OnChanged event.
+func (g *loginGui) onUser(value string) {
+}
+`, buf.String())
+}
+
+func TestExportGoHandlersExisting(t *testing.T) {
+	ctx := DefaultContext()
+	obj := handlersTestTree(ctx)
+
+	existing := `package ui
+
+import "fmt"
+
+// onUser prints the name.
+func (g *loginGui) onUser(s string) {
+	fmt.Println(s)
+}
+`
+	buf := &bytes.Buffer{}
+	require.NoError(t, ExportGoHandlers(obj, ctx, "login", []byte(existing), buf, ExportOptions{Package: "ui"}))
+	assert.Equal(t, `package ui
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2/widget"
+)
+
+// onUser prints the name.
+func (g *loginGui) onUser(s string) {
+	fmt.Println(s)
+}
+
+// onCell handles the OnSelected event.
+func (g *loginGui) onCell(value widget.TableCellID) {
+}
+
+// onLogin handles the OnTapped event.
+func (g *loginGui) onLogin() {
+}
+`, buf.String())
+
+	again := &bytes.Buffer{}
+	require.NoError(t, ExportGoHandlers(obj, ctx, "login", buf.Bytes(), again, ExportOptions{Package: "ui"}))
+	assert.Equal(t, buf.String(), again.String())
+}
+
+func TestExportGoHandlersMismatch(t *testing.T) {
+	ctx := DefaultContext()
+	b := widget.NewButton("Login", nil)
+	ctx.Metadata()[b] = map[string]string{"OnTapped": "g.onEvent"}
+	c := widget.NewCheck("Remember", nil)
+	ctx.Metadata()[c] = map[string]string{"OnChanged": "g.onEvent"}
+
+	err := ExportGoHandlers(container.NewVBox(b, c), ctx, "login", nil, &bytes.Buffer{}, ExportOptions{})
+	assert.Error(t, err)
+}
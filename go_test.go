@@ -2,14 +2,22 @@ package refyne
 
 import (
 	"bytes"
+	"image/color"
 	"sort"
+	"strconv"
+	"strings"
 	"testing"
 
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/fyne-io/refyne/internal/tools"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExportGo(t *testing.T) {
@@ -365,35 +373,344 @@ func (g *whatGui) makeUI() fyne.CanvasObject {
 	assert.Equal(t, exp, buf.String())
 }
 
-func TestStringMapToSlice(t *testing.T) {
-	m := map[string]string{
-		"bam": "4",
-		"bar": "2",
-		"foo": "1",
-		"zap": "3",
+func TestExportGoWithOptions(t *testing.T) {
+	ctx := DefaultContext()
+	l := widget.NewLabel("User")
+	ctx.Metadata()[l] = map[string]string{"name": "user"}
+
+	buf := &bytes.Buffer{}
+	opts := ExportOptions{
+		Package:     "ui",
+		TypeName:    "LoginView",
+		Constructor: "NewLoginView",
+		OmitWindow:  true,
+		Header:      "//go:build ui\n\n// Code generated by GUI builder. DO NOT EDIT.\n",
 	}
-	assert.Equal(t, []string{"4", "2", "1", "3"}, stringMapToSlice(m, func(a, b string) bool {
+	assert.NoError(t, ExportGoWithOptions(container.NewVBox(l), ctx, "login", buf, opts))
+	assert.Equal(t, `//go:build ui
+
+// Code generated by GUI builder. DO NOT EDIT.
+
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+type LoginView struct {
+	user *widget.Label
+}
+
+func NewLoginView() *LoginView {
+	return &LoginView{}
+}
+
+func (g *LoginView) makeUI() fyne.CanvasObject {
+	g.user = widget.NewLabel("User")
+
+	return container.NewVBox(
+		g.user)
+}
+`, buf.String())
+}
+
+func TestExportGoWithoutName(t *testing.T) {
+	ctx := DefaultContext()
+	l := widget.NewLabel("User")
+
+	buf := &bytes.Buffer{}
+	opts := ExportOptions{TypeName: "G", Constructor: "NewG"}
+	require.NoError(t, ExportGoWithOptions(l, ctx, "", buf, opts))
+	assert.Contains(t, buf.String(), "func NewG() *G {")
+
+	buf.Reset()
+	assert.Error(t, ExportGoWithOptions(l, ctx, "", buf, ExportOptions{TypeName: "G"}))
+	assert.Error(t, ExportGo(l, ctx, "", buf))
+}
+
+func TestExportGoDependencyOrder(t *testing.T) {
+	ctx := DefaultContext()
+
+	label := widget.NewLabel("Inside")
+	box := container.NewVBox(label)
+	ctx.Metadata()[box] = map[string]string{"name": "box", "layout": "VBox"}
+	split := container.NewHSplit(box, widget.NewEntry())
+	ctx.Metadata()[split] = map[string]string{"name": "aSplit"}
+	top := container.NewStack(split)
+	ctx.Metadata()[top] = map[string]string{"name": "top", "layout": "Stack"}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, ExportGo(top, ctx, "deps", buf))
+	code := buf.String()
+
+	boxAt := strings.Index(code, "g.box = ")
+	splitAt := strings.Index(code, "g.aSplit = ")
+	topAt := strings.Index(code, "g.top = ")
+	require.NotEqual(t, -1, boxAt)
+	assert.Less(t, boxAt, splitAt)
+	assert.Less(t, splitAt, topAt)
+	assert.Less(t, strings.Index(code, "entry1 := "), splitAt)
+}
+
+func TestExportGoBindings(t *testing.T) {
+	ctx := DefaultContext()
+	e := widget.NewEntry()
+	e.SetText("ignored")
+	ctx.Metadata()[e] = map[string]string{"name": "user", "Data": "username"}
+	l := widget.NewLabel("")
+	ctx.Metadata()[l] = map[string]string{"Data": "username"}
+	c := widget.NewCheck("Remember", nil)
+	ctx.Metadata()[c] = map[string]string{"Data": "remember"}
+	list := CreateNew("*widget.List", ctx)
+	ctx.Metadata()[list] = map[string]string{"Data": "items"}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ExportGo(container.NewVBox(e, l, c, list), ctx, "login", buf))
+	code := buf.String()
+	assert.Contains(t, code, `	"fyne.io/fyne/v2/data/binding"
+`)
+	assert.Contains(t, code, `	user *widget.Entry
+
+	items    binding.StringList
+	remember binding.Bool
+	username binding.String
+}`)
+	assert.Contains(t, code, `	return &loginGui{
+		items:    binding.NewStringList(),
+		remember: binding.NewBool(),
+		username: binding.NewString(),
+	}`)
+	assert.Contains(t, code, "g.user = widget.NewEntryWithData(g.username)")
+	assert.Contains(t, code, "widget.NewLabelWithData(g.username)")
+	assert.Contains(t, code, `widget.NewCheckWithData("Remember", g.remember)`)
+	assert.Contains(t, code, "widget.NewListWithData(g.items, ")
+	assert.NotContains(t, code, "ignored")
+	assert.NotContains(t, code, `"fmt"`)
+
+	ctx2 := DefaultContext()
+	obj, err := ImportGo(bytes.NewReader(buf.Bytes()), ctx2)
+	require.Error(t, err) // the list callbacks are not imported
+	assert.Equal(t, "username", ctx2.Metadata()[obj.(*fyne.Container).Objects[0]]["Data"])
+	again := &bytes.Buffer{}
+	require.NoError(t, ExportGo(obj, ctx2, "login", again))
+	assert.Equal(t, code, again.String())
+
+	ctx.Metadata()[c]["Data"] = "username"
+	assert.EqualError(t, ExportGo(container.NewVBox(e, l, c), ctx, "login", &bytes.Buffer{}),
+		"binding username is used as both String and Bool")
+}
+
+func TestExportGoCollections(t *testing.T) {
+	ctx := DefaultContext()
+	table := CreateNew("*widget.Table", ctx)
+	ctx.Metadata()[table] = map[string]string{"name": "people", "rows": "10", "columns": "2",
+		"headers": "Name, Age", "widths": "120, 80.5", "template": "*widget.Button"}
+	list := CreateNew("*widget.List", ctx)
+	ctx.Metadata()[list] = map[string]string{"length": "3", "template": "*widget.Icon"}
+	tree := CreateNew("*widget.Tree", ctx)
+	ctx.Metadata()[tree] = map[string]string{"items": "Fruit\n  Apple\n  Pear\nVeg\n  Leek"}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ExportGo(container.NewVBox(table, list, tree), ctx, "data", buf))
+	code := buf.String()
+	assert.Contains(t, code, `	g.people = widget.NewTable(func() (int, int) {
+		return 10, 2
+	}, func() fyne.CanvasObject {
+		return widget.NewButton("Cell 000, 000", nil)
+	}, func(id widget.TableCellID, cell fyne.CanvasObject) {
+		cell.(*widget.Button).SetText(fmt.Sprintf("Cell %d, %d", id.Row+1, id.Col+1))
+	})`)
+	assert.Contains(t, code, `	g.people.SetColumnWidth(0, 120)
+	g.people.SetColumnWidth(1, 80.5)
+	g.people.ShowHeaderRow = true
+	g.people.UpdateHeader = func(id widget.TableCellID, template fyne.CanvasObject) {
+		template.(*widget.Label).SetText([]string{"Name", "Age"}[id.Col])
+	}`)
+	assert.Contains(t, code, `		widget.NewList(func() int {
+			return 3
+		}, func() fyne.CanvasObject {
+			return widget.NewIcon(theme.HelpIcon())
+		}, func(id widget.ListItemID, item fyne.CanvasObject) {}),`)
+	assert.Contains(t, code, `			map[string][]string{
+				"":      {"Fruit", "Veg"},
+				"Fruit": {"Apple", "Pear"},
+				"Veg":   {"Leek"},
+			}))`)
+	assert.Contains(t, code, `	"fyne.io/fyne/v2/theme"`)
+}
+
+func TestExportGoCustomResources(t *testing.T) {
+	logo := fyne.NewStaticResource("logo.svg", []byte("<svg/>"))
+	banner := fyne.NewStaticResource("banner.png", []byte("png"))
+	ctx := DefaultContext()
+	ResourcesOf(ctx)["logo"] = Resource{Resource: logo, Embed: "assets/logo.svg"}
+	ResourcesOf(ctx)["banner"] = Resource{Resource: banner, Bundled: "resourceBannerPng"}
+	ResourcesOf(ctx)["unused"] = Resource{Resource: theme.FyneLogo(), Embed: "unused.svg"}
+
+	icon := widget.NewIcon(logo)
+	img := canvas.NewImageFromResource(banner)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ExportGo(container.NewVBox(icon, img), ctx, "branded", buf))
+	code := buf.String()
+	assert.Contains(t, code, `	_ "embed"`)
+	assert.Contains(t, code, `//go:embed assets/logo.svg
+var resourceLogoData []byte
+
+var resourceLogo = fyne.NewStaticResource("logo.svg", resourceLogoData)`)
+	assert.Contains(t, code, "widget.NewIcon(resourceLogo)")
+	assert.Contains(t, code, "canvas.NewImageFromResource(resourceBannerPng)")
+	assert.NotContains(t, code, "unused.svg")
+	assert.NotContains(t, code, `"fyne.io/fyne/v2/theme"`)
+
+	imported, err := ImportGo(strings.NewReader(code), ctx)
+	require.NoError(t, err)
+	objs := imported.(*fyne.Container).Objects
+	assert.Equal(t, logo, objs[0].(*widget.Icon).Resource)
+	assert.Equal(t, banner, objs[1].(*canvas.Image).Resource)
+}
+
+func TestExportGoResourceNameClash(t *testing.T) {
+	dash := fyne.NewStaticResource("icon.svg", []byte("<svg>dash</svg>"))
+	under := fyne.NewStaticResource("icon.svg", []byte("<svg>under</svg>"))
+	ctx := DefaultContext()
+	ResourcesOf(ctx)["my-icon"] = Resource{Resource: dash, Embed: "my icon.svg"}
+	ResourcesOf(ctx)["my_icon"] = Resource{Resource: under, Embed: "my_icon.svg"}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ExportGo(container.NewVBox(widget.NewIcon(dash), widget.NewIcon(under)), ctx, "icons", buf))
+	code := buf.String()
+	assert.Contains(t, code, "//go:embed \"my icon.svg\"\nvar resourceMyIconData []byte")
+	assert.Contains(t, code, "//go:embed my_icon.svg\nvar resourceMyIcon2Data []byte")
+	assert.Contains(t, code, "widget.NewIcon(resourceMyIcon)")
+	assert.Contains(t, code, "widget.NewIcon(resourceMyIcon2)")
+
+	imported, err := ImportGo(strings.NewReader(code), ctx)
+	require.NoError(t, err)
+	objs := imported.(*fyne.Container).Objects
+	assert.Equal(t, dash, objs[0].(*widget.Icon).Resource)
+	assert.Equal(t, under, objs[1].(*widget.Icon).Resource)
+
+	ResourcesOf(ctx)["my_icon"] = Resource{Resource: under, Embed: "../my_icon.svg"}
+	assert.Error(t, ExportGo(widget.NewIcon(under), ctx, "icons", buf))
+}
+
+func TestExportGoThemeReferences(t *testing.T) {
+	ctx := DefaultContext()
+	rect := canvas.NewRectangle(color.Black)
+	rect.StrokeColor = color.Black
+	ctx.Metadata()[rect] = map[string]string{"themeFillColor": "primary", "themeStrokeWidth": "padding"}
+	text := canvas.NewText("Hi", color.White)
+	ctx.Metadata()[text] = map[string]string{"themeColor": "foreground", "themeTextSize": "brand"}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ExportGo(container.NewVBox(rect, text), ctx, "themed", buf))
+	code := buf.String()
+	assert.Contains(t, code, "FillColor: theme.Color(theme.ColorNamePrimary)")
+	assert.Contains(t, code, "StrokeWidth: theme.Size(theme.SizeNamePadding)")
+	assert.Contains(t, code, "Color: theme.Color(theme.ColorNameForeground)")
+	assert.Contains(t, code, `TextSize: theme.Size("brand")`)
+	assert.Contains(t, code, `	"fyne.io/fyne/v2/theme"`)
+	assert.Contains(t, code, `	"image/color"`) // the stroke colour is still a literal
+
+	buf.Reset()
+	delete(ctx.Metadata(), rect)
+	require.NoError(t, ExportGo(text, ctx, "themed", buf))
+	assert.NotContains(t, buf.String(), `"image/color"`)
+}
+
+func TestOrderDefinitions(t *testing.T) {
+	byName := func(a, b string) bool {
 		return a < b
-	}))
-
-	m2 := map[string]string{
-		"bam": "a",
-		"bar": "b",
-		"foo": "c",
-		"moo": "d",
-		"zap": "e",
 	}
-	d2 := map[string]int{
-		"bam": 4,
-		"bar": 2,
-		"foo": 1,
-		"moo": 1,
-		"zap": 3,
+	lines := map[string]string{"a": "", "b": "", "c": "", "label1": ""}
+	defs := map[string]string{
+		"a":      "container.NewVBox(g.c, label1)",
+		"b":      "widget.NewLabel(\"g.a\")",
+		"c":      "widget.NewButton(\"Hi\", nil)",
+		"label1": "widget.NewLabel(\"Hi\")",
+	}
+	generated := map[string]bool{"label1": true}
+
+	order, err := orderDefinitions(lines, defs, generated, nil, byName)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c", "label1", "a"}, order)
+
+	order, err = orderDefinitions(lines, defs, generated, map[string][]string{"b": {"a"}}, byName)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "label1", "a", "b"}, order)
+
+	defs["c"] = "container.NewStack(g.b)"
+	_, err = orderDefinitions(lines, defs, generated, map[string][]string{"b": {"a"}}, byName)
+	assert.EqualError(t, err, "dependency cycle in GUI definitions: a -> c -> b -> a")
+}
+
+type testColumnsLayout struct {
+	cols int
+}
+
+func (l *testColumnsLayout) Layout(objs []fyne.CanvasObject, size fyne.Size) {
+	layout.NewGridLayoutWithColumns(l.cols).Layout(objs, size)
+}
+
+func (l *testColumnsLayout) MinSize(objs []fyne.CanvasObject) fyne.Size {
+	return layout.NewGridLayoutWithColumns(l.cols).MinSize(objs)
+}
+
+func TestRegisterLayoutWithoutGostring(t *testing.T) {
+	create := func(*fyne.Container, Context) fyne.Layout {
+		return layout.NewStackLayout()
 	}
-	assert.Equal(t, []string{"c", "d", "b", "e", "a"}, stringMapToSlice(m2, func(a, b string) bool {
-		if d2[a] == d2[b] {
-			return a < b
-		}
-		return d2[a] < d2[b]
-	}))
+	assert.Error(t, RegisterLayout("Masonry", LayoutInfo{Create: create}))
+	assert.Error(t, RegisterLayout("Masonry", LayoutInfo{}))
+	assert.NotContains(t, LayoutList(), "Masonry")
+
+	reg := NewRegistry()
+	assert.NoError(t, reg.RegisterLayout("VBox", LayoutInfo{Create: create}))
+}
+
+func TestExportGoRegisteredLayout(t *testing.T) {
+	reg := NewRegistry()
+	err := reg.RegisterLayout("Columns", LayoutInfo{
+		Create: func(c *fyne.Container, d Context) fyne.Layout {
+			cols, _ := strconv.Atoi(d.Metadata()[c]["columns"])
+			return &testColumnsLayout{cols: cols}
+		},
+		Gostring: func(c *fyne.Container, d Context, defs map[string]string) string {
+			return "container.New(columns.New(" + d.Metadata()[c]["columns"] + "), " +
+				ChildrenGoString(d, defs, c.Objects...) + ")"
+		},
+		Properties: map[string]string{"columns": "the number of columns"},
+		Packages: func(*fyne.Container, Context) []string {
+			return []string{"example.com/columns"}
+		},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, reg.LayoutNames(), "Columns")
+	assert.True(t, sort.StringsAreSorted(reg.LayoutNames()))
+	assert.NotContains(t, LayoutList(), "Columns")
+
+	ctx := ContextWithRegistry(reg)
+	c := container.New(&testColumnsLayout{cols: 3}, widget.NewLabel("a"), widget.NewLabel("b"))
+	ctx.Metadata()[c] = map[string]string{"layout": "Columns", "columns": "3"}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, EncodeObject(c, ctx, buf))
+	assert.Contains(t, buf.String(), `"Layout": "Columns"`)
+
+	decoded, err := DecodeObject(buf, ctx)
+	require.NoError(t, err)
+	dc := decoded.(*fyne.Container)
+	assert.Equal(t, 3, dc.Layout.(*testColumnsLayout).cols)
+	assert.Len(t, dc.Objects, 2)
+
+	buf.Reset()
+	require.NoError(t, ExportGo(c, ctx, "columns", buf))
+	code := buf.String()
+	assert.Contains(t, code, `container.New(columns.New(3),
+		widget.NewLabel("a"),
+		widget.NewLabel("b"))`)
+	assert.Contains(t, code, `	"example.com/columns"`)
 }
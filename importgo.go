@@ -0,0 +1,1200 @@
+package refyne
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"image/color"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	xWidget "fyne.io/x/fyne/widget"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+)
+
+var (
+	// goFuncs are the functions that may be called by imported code, keyed by their qualified name
+	goFuncs = map[string]interface{}{
+		"canvas.NewCircle":            canvas.NewCircle,
+		"canvas.NewImageFromFile":     canvas.NewImageFromFile,
+		"canvas.NewImageFromResource": canvas.NewImageFromResource,
+		"canvas.NewLine":              canvas.NewLine,
+		"canvas.NewLinearGradient":    canvas.NewLinearGradient,
+		"canvas.NewRadialGradient":    canvas.NewRadialGradient,
+		"canvas.NewRectangle":         canvas.NewRectangle,
+		"canvas.NewText":              canvas.NewText,
+
+		"container.NewAppTabs":             container.NewAppTabs,
+		"container.NewClip":                container.NewClip,
+		"container.NewHScroll":             container.NewHScroll,
+		"container.NewHSplit":              container.NewHSplit,
+		"container.NewNavigation":          container.NewNavigation,
+		"container.NewNavigationWithTitle": container.NewNavigationWithTitle,
+		"container.NewScroll":              container.NewScroll,
+		"container.NewTabItem":             container.NewTabItem,
+		"container.NewTabItemWithIcon":     container.NewTabItemWithIcon,
+		"container.NewVScroll":             container.NewVScroll,
+		"container.NewVSplit":              container.NewVSplit,
+
+		"fyne.NewPos":        fyne.NewPos,
+		"fyne.NewSize":       fyne.NewSize,
+		"fyne.NewSquareSize": fyne.NewSquareSize,
+
+		"layout.NewSpacer": layout.NewSpacer,
+
+		"theme.Padding": theme.Padding,
+
+		"widget.NewAccordion":            widget.NewAccordion,
+		"widget.NewAccordionItem":        widget.NewAccordionItem,
+		"widget.NewActivity":             widget.NewActivity,
+		"widget.NewButton":               widget.NewButton,
+		"widget.NewButtonWithIcon":       widget.NewButtonWithIcon,
+		"widget.NewCard":                 widget.NewCard,
+		"widget.NewCheck":                widget.NewCheck,
+		"widget.NewCheckWithData":        widget.NewCheckWithData,
+		"widget.NewDateEntry":            widget.NewDateEntry,
+		"widget.NewEntry":                widget.NewEntry,
+		"widget.NewEntryWithData":        widget.NewEntryWithData,
+		"widget.NewForm":                 widget.NewForm,
+		"widget.NewFormItem":             widget.NewFormItem,
+		"widget.NewHyperlink":            widget.NewHyperlink,
+		"widget.NewIcon":                 widget.NewIcon,
+		"widget.NewLabel":                widget.NewLabel,
+		"widget.NewLabelWithData":        widget.NewLabelWithData,
+		"widget.NewLabelWithStyle":       widget.NewLabelWithStyle,
+		"widget.NewList":                 widget.NewList,
+		"widget.NewListWithData":         widget.NewListWithData,
+		"widget.NewMultiLineEntry":       widget.NewMultiLineEntry,
+		"widget.NewPasswordEntry":        widget.NewPasswordEntry,
+		"widget.NewProgressBar":          widget.NewProgressBar,
+		"widget.NewProgressBarInfinite":  widget.NewProgressBarInfinite,
+		"widget.NewProgressBarWithData":  widget.NewProgressBarWithData,
+		"widget.NewRadioGroup":           widget.NewRadioGroup,
+		"widget.NewRichTextFromMarkdown": widget.NewRichTextFromMarkdown,
+		"widget.NewSelect":               widget.NewSelect,
+		"widget.NewSeparator":            widget.NewSeparator,
+		"widget.NewSlider":               widget.NewSlider,
+		"widget.NewSliderWithData":       widget.NewSliderWithData,
+		"widget.NewTable":                widget.NewTable,
+		"widget.NewTextGrid":             widget.NewTextGrid,
+		"widget.NewToolbar":              widget.NewToolbar,
+		"widget.NewToolbarAction":        widget.NewToolbarAction,
+		"widget.NewToolbarSeparator":     widget.NewToolbarSeparator,
+		"widget.NewToolbarSpacer":        widget.NewToolbarSpacer,
+		"widget.NewTreeWithStrings":      widget.NewTreeWithStrings,
+
+		"xWidget.AtLatLon":          xWidget.AtLatLon,
+		"xWidget.AtZoomLevel":       xWidget.AtZoomLevel,
+		"xWidget.NewMapWithOptions": xWidget.NewMapWithOptions,
+	}
+
+	// goBindingFuncs are the constructors of bound widgets, with the position of the binding argument
+	goBindingFuncs = map[string]int{
+		"widget.NewCheckWithData":       1,
+		"widget.NewEntryWithData":       0,
+		"widget.NewLabelWithData":       0,
+		"widget.NewListWithData":        0,
+		"widget.NewProgressBarWithData": 0,
+		"widget.NewSliderWithData":      2,
+	}
+
+	// goBindings create a binding of each type that widgets can be bound to while they are imported
+	goBindings = map[string]func() interface{}{
+		"Bool":       func() interface{} { return binding.NewBool() },
+		"Float":      func() interface{} { return binding.NewFloat() },
+		"String":     func() interface{} { return binding.NewString() },
+		"StringList": func() interface{} { return binding.NewStringList() },
+	}
+
+	// goConstants are the named values that may be used by imported code
+	goConstants = map[string]interface{}{
+		"canvas.ImageFillContain":  canvas.ImageFillContain,
+		"canvas.ImageFillCover":    canvas.ImageFillCover,
+		"canvas.ImageFillOriginal": canvas.ImageFillOriginal,
+		"canvas.ImageFillStretch":  canvas.ImageFillStretch,
+
+		"color.Black":       color.Black,
+		"color.Transparent": color.Transparent,
+		"color.White":       color.White,
+
+		"container.ScrollBoth":           container.ScrollBoth,
+		"container.ScrollHorizontalOnly": container.ScrollHorizontalOnly,
+		"container.ScrollNone":           container.ScrollNone,
+		"container.ScrollVerticalOnly":   container.ScrollVerticalOnly,
+		"container.TabLocationBottom":    container.TabLocationBottom,
+		"container.TabLocationLeading":   container.TabLocationLeading,
+		"container.TabLocationTop":       container.TabLocationTop,
+		"container.TabLocationTrailing":  container.TabLocationTrailing,
+
+		"fyne.TextAlignCenter":   fyne.TextAlignCenter,
+		"fyne.TextAlignLeading":  fyne.TextAlignLeading,
+		"fyne.TextAlignTrailing": fyne.TextAlignTrailing,
+		"fyne.TextWrapBreak":     fyne.TextWrapBreak,
+		"fyne.TextWrapOff":       fyne.TextWrapOff,
+		"fyne.TextWrapWord":      fyne.TextWrapWord,
+
+		"widget.ButtonAlignCenter":   widget.ButtonAlignCenter,
+		"widget.ButtonAlignLeading":  widget.ButtonAlignLeading,
+		"widget.ButtonAlignTrailing": widget.ButtonAlignTrailing,
+		"widget.DangerImportance":    widget.DangerImportance,
+		"widget.HighImportance":      widget.HighImportance,
+		"widget.Horizontal":          widget.Horizontal,
+		"widget.LowImportance":       widget.LowImportance,
+		"widget.MediumImportance":    widget.MediumImportance,
+		"widget.SuccessImportance":   widget.SuccessImportance,
+		"widget.Vertical":            widget.Vertical,
+		"widget.WarningImportance":   widget.WarningImportance,
+	}
+
+	// goTypes are the types that may be used in composite literals and conversions,
+	// widget types are added from the registered definitions when first needed
+	goTypes = map[string]reflect.Type{
+		"color.Color":          reflect.TypeOf((*color.Color)(nil)).Elem(),
+		"color.Gray16":         reflect.TypeOf(color.Gray16{}),
+		"color.NRGBA":          reflect.TypeOf(color.NRGBA{}),
+		"color.RGBA":           reflect.TypeOf(color.RGBA{}),
+		"container.TabItem":    reflect.TypeOf(container.TabItem{}),
+		"fyne.CanvasObject":    reflect.TypeOf((*fyne.CanvasObject)(nil)).Elem(),
+		"fyne.Position":        reflect.TypeOf(fyne.Position{}),
+		"fyne.Resource":        reflect.TypeOf((*fyne.Resource)(nil)).Elem(),
+		"fyne.Size":            reflect.TypeOf(fyne.Size{}),
+		"fyne.TextStyle":       reflect.TypeOf(fyne.TextStyle{}),
+		"url.URL":              reflect.TypeOf(url.URL{}),
+		"url.Userinfo":         reflect.TypeOf(url.Userinfo{}),
+		"widget.AccordionItem": reflect.TypeOf(widget.AccordionItem{}),
+		"widget.FormItem":      reflect.TypeOf(widget.FormItem{}),
+		"widget.ToolbarItem":   reflect.TypeOf((*widget.ToolbarItem)(nil)).Elem(),
+	}
+
+	goBasicTypes = map[string]reflect.Type{
+		"bool":    reflect.TypeOf(false),
+		"float32": reflect.TypeOf(float32(0)),
+		"float64": reflect.TypeOf(float64(0)),
+		"int":     reflect.TypeOf(0),
+		"int64":   reflect.TypeOf(int64(0)),
+		"string":  reflect.TypeOf(""),
+		"uint8":   reflect.TypeOf(uint8(0)),
+		"uint16":  reflect.TypeOf(uint16(0)),
+	}
+
+	canvasObjectType = reflect.TypeOf((*fyne.CanvasObject)(nil)).Elem()
+)
+
+// ImportGo reads Go source in the form written by `ExportGo` and returns the tree of `CanvasObject` elements
+// that its `makeUI` method creates. Names, callbacks and layouts are added to the context metadata,
+// so that the code can be edited in the GUI builder and exported again.
+// Code that cannot be understood is skipped and reported in a `*DecodeError`, where the path of each issue
+// is the line and column of the source.
+func ImportGo(r io.Reader, d Context) (fyne.CanvasObject, error) {
+	guidefs.InitOnce()
+
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var makeUI *ast.FuncDecl
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "makeUI" && fn.Recv != nil {
+			makeUI = fn
+			break
+		}
+	}
+	if makeUI == nil || makeUI.Body == nil {
+		return nil, errors.New("no makeUI method found")
+	}
+
+	im := &goImporter{
+		decoder: &decoder{ctx: d},
+		fset:    fset,
+		fields:  make(map[string]reflect.Value),
+		locals:  make(map[string]reflect.Value),
+	}
+	if names := makeUI.Recv.List[0].Names; len(names) > 0 {
+		im.recv = names[0].Name
+	}
+
+	ret, ok := im.exec(makeUI.Body.List)
+	if !ok || !ret.IsValid() {
+		im.report(makeUI.Body.Rbrace, "", SeverityError, "makeUI does not return an object")
+		return nil, im.err()
+	}
+	obj, ok := ret.Interface().(fyne.CanvasObject)
+	if !ok || isNilValue(reflect.ValueOf(obj)) {
+		im.report(makeUI.Body.Rbrace, "", SeverityError, "makeUI does not return an object")
+		return nil, im.err()
+	}
+
+	im.finish()
+	return obj, im.err()
+}
+
+type goImporter struct {
+	*decoder
+
+	fset    *token.FileSet
+	recv    string
+	fields  map[string]reflect.Value
+	locals  map[string]reflect.Value
+	created []fyne.CanvasObject
+}
+
+func (im *goImporter) report(pos token.Pos, class string, severity Severity, msg string) {
+	p := im.fset.Position(pos)
+	im.decoder.report(fmt.Sprintf("%d:%d", p.Line, p.Column), class, severity, msg)
+}
+
+// exec runs the statements of a function body, returning the value of its return statement.
+func (im *goImporter) exec(stmts []ast.Stmt) (reflect.Value, bool) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			im.assign(s)
+		case *ast.ExprStmt:
+			if _, err := im.eval(s.X, nil); err != nil {
+				im.report(s.Pos(), "", SeverityWarning, err.Error())
+			}
+		case *ast.ReturnStmt:
+			if len(s.Results) != 1 {
+				return reflect.Value{}, false
+			}
+			v, err := im.eval(s.Results[0], canvasObjectType)
+			if err != nil {
+				im.report(s.Pos(), "", SeverityError, err.Error())
+				return reflect.Value{}, false
+			}
+			return v, true
+		default:
+			im.report(s.Pos(), "", SeverityWarning, "unsupported statement")
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+func (im *goImporter) assign(s *ast.AssignStmt) {
+	if len(s.Lhs) > 1 && len(s.Rhs) == 1 {
+		call, ok := s.Rhs[0].(*ast.CallExpr)
+		if !ok {
+			im.report(s.Pos(), "", SeverityWarning, "unsupported assignment")
+			return
+		}
+		vals, err := im.call(call, nil)
+		if err != nil {
+			im.report(s.Pos(), "", SeverityWarning, err.Error())
+			return
+		}
+		for i, lhs := range s.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok && id.Name != "_" && i < len(vals) {
+				im.locals[id.Name] = vals[i]
+			}
+		}
+		return
+	}
+	if len(s.Lhs) != len(s.Rhs) {
+		im.report(s.Pos(), "", SeverityWarning, "unsupported assignment")
+		return
+	}
+
+	for i, lhs := range s.Lhs {
+		rhs := s.Rhs[i]
+		switch l := lhs.(type) {
+		case *ast.Ident:
+			v, err := im.evalValue(rhs, nil)
+			if err != nil {
+				im.report(rhs.Pos(), "", SeverityWarning, err.Error())
+				continue
+			}
+			im.locals[l.Name] = v
+		case *ast.SelectorExpr:
+			if id, ok := l.X.(*ast.Ident); ok && id.Name == im.recv {
+				im.assignField(l.Sel.Name, rhs)
+				continue
+			}
+			im.assignAttribute(l, rhs)
+		default:
+			im.report(s.Pos(), "", SeverityWarning, "unsupported assignment")
+		}
+	}
+}
+
+// assignField handles a line such as `g.button1 = widget.NewButton("Tap", nil)`, which names an object.
+func (im *goImporter) assignField(name string, rhs ast.Expr) {
+	v, err := im.evalValue(rhs, nil)
+	if err != nil {
+		im.report(rhs.Pos(), "", SeverityWarning, err.Error())
+		return
+	}
+	im.fields[name] = v
+
+	if obj, ok := v.Interface().(fyne.CanvasObject); ok && obj != nil {
+		im.track(obj)
+		im.ctx.Metadata()[obj]["name"] = name
+	}
+}
+
+// assignAttribute handles a line such as `g.button1.Importance = 2` or `g.button1.OnTapped = g.win.Close`.
+func (im *goImporter) assignAttribute(l *ast.SelectorExpr, rhs ast.Expr) {
+	target, err := im.evalValue(l.X, nil)
+	if err != nil {
+		im.report(l.Pos(), "", SeverityWarning, err.Error())
+		return
+	}
+	obj, _ := target.Interface().(fyne.CanvasObject)
+	class := ""
+	if obj != nil {
+		class = guidefs.RegistryOf(im.ctx).TypeName(obj)
+	}
+
+	for target.Kind() == reflect.Ptr || target.Kind() == reflect.Interface {
+		target = target.Elem()
+	}
+	if target.Kind() != reflect.Struct {
+		im.report(l.Pos(), class, SeverityWarning, "cannot set "+l.Sel.Name)
+		return
+	}
+	field := target.FieldByName(l.Sel.Name)
+	if !field.IsValid() || !field.CanSet() {
+		im.report(l.Sel.Pos(), class, SeverityWarning, "unknown field "+l.Sel.Name)
+		return
+	}
+
+	if field.Kind() == reflect.Func && obj != nil {
+		if _, isLit := rhs.(*ast.FuncLit); !isLit && !isNil(rhs) {
+			im.track(obj)
+			im.ctx.Metadata()[obj][l.Sel.Name] = im.source(rhs)
+			return
+		}
+	}
+
+	v, err := im.evalValue(rhs, field.Type())
+	if err != nil {
+		im.report(rhs.Pos(), class, SeverityWarning, err.Error())
+		return
+	}
+	field.Set(v)
+}
+
+// evalValue is like eval but returns an error if the expression does not have a value,
+// such as a call to a method that returns nothing.
+func (im *goImporter) evalValue(e ast.Expr, want reflect.Type) (reflect.Value, error) {
+	v, err := im.eval(e, want)
+	if err == nil && !v.IsValid() {
+		err = errors.New(im.source(e) + " does not have a value")
+	}
+	return v, err
+}
+
+func (im *goImporter) eval(e ast.Expr, want reflect.Type) (reflect.Value, error) {
+	switch x := e.(type) {
+	case *ast.ParenExpr:
+		return im.eval(x.X, want)
+	case *ast.BasicLit:
+		v, err := basicLiteral(x)
+		if err != nil {
+			return v, err
+		}
+		return convertValue(v, want)
+	case *ast.Ident:
+		switch x.Name {
+		case "true", "false":
+			return convertValue(reflect.ValueOf(x.Name == "true"), want)
+		case "nil":
+			if want == nil {
+				return reflect.Value{}, errors.New("untyped nil")
+			}
+			return reflect.Zero(want), nil
+		}
+		if v, ok := im.locals[x.Name]; ok {
+			return convertValue(v, want)
+		}
+		vars := guidefs.ResourceVariables(im.ctx)
+		for _, name := range guidefs.ResourceNames(im.ctx) {
+			if vars[name] == x.Name {
+				r := guidefs.ResourcesOf(im.ctx)[name]
+				return convertValue(reflect.ValueOf(&r.Resource).Elem(), want)
+			}
+		}
+		return reflect.Value{}, errors.New("unknown identifier " + x.Name)
+	case *ast.SelectorExpr:
+		if id, ok := x.X.(*ast.Ident); ok {
+			if id.Name == im.recv {
+				v, ok := im.fields[x.Sel.Name]
+				if !ok {
+					return reflect.Value{}, errors.New("unknown field " + x.Sel.Name)
+				}
+				return convertValue(v, want)
+			}
+			if _, local := im.locals[id.Name]; !local {
+				if c, ok := goConstants[id.Name+"."+x.Sel.Name]; ok {
+					return convertValue(reflect.ValueOf(c), want)
+				}
+				return reflect.Value{}, errors.New("unknown value " + id.Name + "." + x.Sel.Name)
+			}
+		}
+
+		v, err := im.evalValue(x.X, nil)
+		if err != nil {
+			return v, err
+		}
+		if m := v.MethodByName(x.Sel.Name); m.IsValid() {
+			return convertValue(m, want)
+		}
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Struct {
+			if f := v.FieldByName(x.Sel.Name); f.IsValid() {
+				return convertValue(f, want)
+			}
+		}
+		return reflect.Value{}, errors.New("unknown field " + x.Sel.Name)
+	case *ast.CallExpr:
+		vals, err := im.call(x, want)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if len(vals) == 0 {
+			if want != nil {
+				return reflect.Value{}, errors.New(im.source(x) + " does not have a value")
+			}
+			return reflect.Value{}, nil
+		}
+		return convertValue(vals[0], want)
+	case *ast.UnaryExpr:
+		switch x.Op {
+		case token.AND:
+			lit, ok := x.X.(*ast.CompositeLit)
+			if !ok {
+				break
+			}
+			t := im.resolveType(lit.Type)
+			if t == nil && want != nil && want.Kind() == reflect.Ptr {
+				t = want.Elem()
+			}
+			v, err := im.composite(lit, t)
+			if err != nil {
+				return v, err
+			}
+			ptr := addressOf(v)
+			if w, ok := ptr.Interface().(fyne.Widget); ok {
+				if ext, ok := w.(interface{ ExtendBaseWidget(fyne.Widget) }); ok {
+					ext.ExtendBaseWidget(w)
+				}
+				im.track(w)
+			}
+			return convertValue(ptr, want)
+		case token.SUB:
+			v, err := im.eval(x.X, want)
+			if err != nil {
+				return v, err
+			}
+			neg := reflect.New(v.Type()).Elem()
+			switch {
+			case v.CanInt():
+				neg.SetInt(-v.Int())
+			case v.CanFloat():
+				neg.SetFloat(-v.Float())
+			default:
+				return v, errors.New("cannot negate " + v.Type().String())
+			}
+			return neg, nil
+		}
+	case *ast.CompositeLit:
+		t := im.resolveType(x.Type)
+		if t == nil {
+			t = want
+		}
+		if t != nil && t.Kind() == reflect.Ptr {
+			v, err := im.composite(x, t.Elem())
+			if err != nil {
+				return v, err
+			}
+			return addressOf(v), nil
+		}
+		return im.composite(x, t)
+	case *ast.StarExpr:
+		v, err := im.evalValue(x.X, nil)
+		if err != nil {
+			return v, err
+		}
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			return reflect.Value{}, fmt.Errorf("%s is not a pointer to a value", im.source(x.X))
+		}
+		return convertValue(v.Elem(), want)
+	case *ast.FuncLit:
+		if want != nil && want.Kind() == reflect.Func && want.NumOut() == 0 {
+			return reflect.MakeFunc(want, func([]reflect.Value) []reflect.Value { return nil }), nil
+		}
+		return reflect.Value{}, errors.New("unsupported function literal")
+	}
+
+	return reflect.Value{}, fmt.Errorf("unsupported expression %s", im.source(e))
+}
+
+func (im *goImporter) call(x *ast.CallExpr, want reflect.Type) ([]reflect.Value, error) {
+	switch fun := x.Fun.(type) {
+	case *ast.FuncLit: // an inline function that is called immediately, such as setting the tab location
+		if len(fun.Type.Params.List) > 0 {
+			break
+		}
+		ret, ok := im.exec(fun.Body.List)
+		if !ok {
+			return nil, errors.New("function does not return a value")
+		}
+		return []reflect.Value{ret}, nil
+	case *ast.Ident:
+		if t, ok := goBasicTypes[fun.Name]; ok && len(x.Args) == 1 {
+			v, err := im.eval(x.Args[0], t)
+			return []reflect.Value{v}, err
+		}
+		if fun.Name == "wrapLayout" {
+			return nil, errors.New("wrapLayout can only be used in container.New")
+		}
+	case *ast.ParenExpr:
+		if t := im.resolveType(fun.X); t != nil && len(x.Args) == 1 {
+			if isNil(x.Args[0]) {
+				return nil, fmt.Errorf("conversion of nil to %s is not supported", t)
+			}
+			v, err := im.eval(x.Args[0], t)
+			return []reflect.Value{v}, err
+		}
+	case *ast.SelectorExpr:
+		if id, ok := fun.X.(*ast.Ident); ok && id.Name != im.recv {
+			if _, local := im.locals[id.Name]; !local {
+				return im.callPackage(id.Name+"."+fun.Sel.Name, x)
+			}
+		}
+
+		recv, err := im.evalValue(fun.X, nil)
+		if err != nil {
+			return nil, err
+		}
+		m := recv.MethodByName(fun.Sel.Name)
+		if !m.IsValid() {
+			return nil, errors.New("unknown method " + fun.Sel.Name)
+		}
+		vals, err := im.callFunc(m, x.Args)
+		if err == nil && fun.Sel.Name == "SetTabLocation" {
+			if tabs, ok := recv.Interface().(*container.AppTabs); ok {
+				im.track(tabs)
+				im.ctx.Metadata()[tabs]["location"] = tabLocationName(x.Args[0])
+			}
+		}
+		return vals, err
+	}
+
+	return nil, fmt.Errorf("unsupported call %s", im.source(x.Fun))
+}
+
+// callPackage calls a function such as `widget.NewLabel`, including the container constructors that set a layout.
+func (im *goImporter) callPackage(name string, x *ast.CallExpr) ([]reflect.Value, error) {
+	if obj, ok, err := im.layoutContainer(name, x); ok {
+		if err != nil {
+			return nil, err
+		}
+		return []reflect.Value{reflect.ValueOf(obj)}, nil
+	}
+
+	switch name {
+	case "container.NewThemeOverride":
+		return im.themeOverride(x)
+	case "widget.NewCheckWithData", "widget.NewEntryWithData", "widget.NewLabelWithData", "widget.NewListWithData",
+		"widget.NewProgressBarWithData", "widget.NewSliderWithData":
+		return im.boundWidget(name, x)
+	case "widget.NewHyperlink":
+		if len(x.Args) == 2 { // the URL is written as a struct literal
+			text, err := im.eval(x.Args[0], goBasicTypes["string"])
+			if err != nil {
+				return nil, err
+			}
+			u, err := im.eval(x.Args[1], reflect.PtrTo(goTypes["url.URL"]))
+			if err != nil {
+				return nil, err
+			}
+			link := widget.NewHyperlink(text.String(), u.Interface().(*url.URL))
+			im.track(link)
+			return []reflect.Value{reflect.ValueOf(link)}, nil
+		}
+	}
+
+	if pkg, icon, ok := strings.Cut(name, "."); ok && pkg == "theme" && len(x.Args) == 0 {
+		if res := guidefs.RegistryOf(im.ctx).Icon(icon); res != nil {
+			return []reflect.Value{reflect.ValueOf(&res).Elem()}, nil
+		}
+	}
+
+	fn, ok := goFuncs[name]
+	if !ok {
+		return nil, errors.New("unknown function " + name)
+	}
+	f := reflect.ValueOf(fn)
+	vals, err := im.callFunc(f, x.Args)
+	if err != nil {
+		// fall back to the default object of the type, for example a list with callbacks that can't be imported
+		out := f.Type().Out(0)
+		info := guidefs.RegistryOf(im.ctx).Lookup(out.String())
+		if info == nil || info.Create == nil {
+			return nil, err
+		}
+		im.report(x.Pos(), out.String(), SeverityWarning, "using default "+info.Name+": "+err.Error())
+		vals = []reflect.Value{reflect.ValueOf(info.Create(im.ctx))}
+	}
+
+	if len(vals) > 0 {
+		if obj, ok := vals[0].Interface().(fyne.CanvasObject); ok {
+			im.track(obj)
+			im.setProperties(name, obj, x)
+		}
+	}
+	return vals, nil
+}
+
+// boundWidget creates a widget from a constructor that takes a data binding.
+// The binding field is recorded in the metadata and the widget is left unbound, as it would be in the editor.
+func (im *goImporter) boundWidget(name string, x *ast.CallExpr) ([]reflect.Value, error) {
+	f := reflect.ValueOf(goFuncs[name])
+	at := goBindingFuncs[name]
+	if len(x.Args) != f.Type().NumIn() {
+		return nil, fmt.Errorf("wrong number of arguments to %s", name)
+	}
+	sel, ok := x.Args[at].(*ast.SelectorExpr)
+	if !ok {
+		return nil, fmt.Errorf("the binding passed to %s should be a field", name)
+	}
+	if id, ok := sel.X.(*ast.Ident); !ok || id.Name != im.recv {
+		return nil, fmt.Errorf("the binding passed to %s should be a field", name)
+	}
+
+	class := f.Type().Out(0).String()
+	args := make([]reflect.Value, len(x.Args))
+	for i, arg := range x.Args {
+		if i == at {
+			args[i] = reflect.ValueOf(goBindings[guidefs.BindingTypes[class]]())
+			continue
+		}
+
+		v, err := im.eval(arg, f.Type().In(i))
+		if err != nil {
+			info := guidefs.RegistryOf(im.ctx).Lookup(class)
+			im.report(x.Pos(), class, SeverityWarning, "using default "+info.Name+": "+err.Error())
+			args = nil
+			break
+		}
+		args[i] = v
+	}
+
+	var obj fyne.CanvasObject
+	if args == nil {
+		obj = guidefs.RegistryOf(im.ctx).Lookup(class).Create(im.ctx)
+	} else {
+		obj = f.Call(args)[0].Interface().(fyne.CanvasObject)
+		if bound, ok := obj.(interface{ Unbind() }); ok {
+			bound.Unbind()
+		}
+	}
+	im.track(obj)
+	im.ctx.Metadata()[obj]["Data"] = sel.Sel.Name
+	return []reflect.Value{reflect.ValueOf(obj)}, nil
+}
+
+// setProperties adds the metadata that the code generator uses for objects created by some functions.
+func (im *goImporter) setProperties(name string, obj fyne.CanvasObject, x *ast.CallExpr) {
+	props := im.ctx.Metadata()[obj]
+	switch name {
+	case "widget.NewRichTextFromMarkdown":
+		if len(x.Args) == 1 {
+			if v, err := im.eval(x.Args[0], goBasicTypes["string"]); err == nil {
+				props["text"] = v.String()
+			}
+		}
+	case "xWidget.NewMapWithOptions":
+		for _, arg := range x.Args {
+			opt, ok := arg.(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			switch im.source(opt.Fun) {
+			case "xWidget.AtLatLon":
+				if len(opt.Args) == 2 {
+					props["lat"] = im.formatNumber(opt.Args[0])
+					props["lon"] = im.formatNumber(opt.Args[1])
+				}
+			case "xWidget.AtZoomLevel":
+				if len(opt.Args) == 1 {
+					props["zoom"] = im.formatNumber(opt.Args[0])
+				}
+			}
+		}
+	}
+}
+
+func (im *goImporter) callFunc(f reflect.Value, args []ast.Expr) (vals []reflect.Value, err error) {
+	t := f.Type()
+	if (!t.IsVariadic() && len(args) != t.NumIn()) || (t.IsVariadic() && len(args) < t.NumIn()-1) {
+		return nil, fmt.Errorf("expected %d arguments but found %d", t.NumIn(), len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var pt reflect.Type
+		if t.IsVariadic() && i >= t.NumIn()-1 {
+			pt = t.In(t.NumIn() - 1).Elem()
+		} else {
+			pt = t.In(i)
+		}
+
+		if _, isLit := arg.(*ast.FuncLit); isLit && pt.Kind() == reflect.Func && pt.NumOut() > 0 {
+			return nil, errors.New("functions that return values are not supported")
+		}
+		if in[i], err = im.eval(arg, pt); err != nil {
+			return nil, err
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("call failed: %v", r)
+		}
+	}()
+	return f.Call(in), nil
+}
+
+// layoutContainer creates a container from functions such as `container.NewVBox` or `container.New(layout, ...)`.
+// It returns false if the function does not create a container with a layout.
+func (im *goImporter) layoutContainer(name string, x *ast.CallExpr) (fyne.CanvasObject, bool, error) {
+	props := map[string]string{}
+	args := x.Args
+	var edges []fyne.CanvasObject
+	var positions map[int]map[string]string
+
+	layoutName := strings.TrimPrefix(name, "container.New")
+	switch name {
+	case "container.New":
+		if len(args) == 0 {
+			return nil, true, errors.New("container.New needs a layout")
+		}
+		var err error
+		layoutName, positions, err = im.layoutOf(args[0], props)
+		if err != nil {
+			return nil, true, err
+		}
+		args = args[1:]
+	case "container.NewBorder":
+		if len(args) < 4 {
+			return nil, true, errors.New("container.NewBorder needs 4 border objects")
+		}
+		for _, arg := range args[:4] {
+			v, err := im.eval(arg, canvasObjectType)
+			if err != nil {
+				return nil, true, err
+			}
+			obj, _ := v.Interface().(fyne.CanvasObject)
+			edges = append(edges, obj)
+		}
+		args = args[4:]
+	case "container.NewGridWithColumns", "container.NewGridWithRows":
+		if len(args) == 0 {
+			return nil, true, errors.New(name + " needs a count")
+		}
+		layoutName = "Grid"
+		props["grid_type"] = strings.TrimPrefix(name, "container.NewGridWith")
+		props["count"] = im.formatNumber(args[0])
+		args = args[1:]
+	default:
+		if !strings.HasPrefix(name, "container.New") {
+			return nil, false, nil
+		}
+		if info, ok := guidefs.RegistryOf(im.ctx).Layout(layoutName); !ok || len(info.Properties) > 0 || info.Gostring != nil || layoutName == "WithoutLayout" {
+			return nil, false, nil
+		}
+	}
+
+	var objs []fyne.CanvasObject
+	for _, arg := range args {
+		v, err := im.eval(arg, canvasObjectType)
+		if err != nil {
+			im.report(arg.Pos(), "*fyne.Container", SeverityWarning, err.Error())
+			continue
+		}
+		if obj, ok := v.Interface().(fyne.CanvasObject); ok && obj != nil {
+			objs = append(objs, obj)
+		}
+	}
+	for i, edge := range edges {
+		if edge == nil {
+			continue
+		}
+		props[[]string{"top", "bottom", "left", "right"}[i]] = strconv.Itoa(len(objs))
+		objs = append(objs, edge)
+	}
+	for i, pos := range positions {
+		if i >= len(objs) {
+			continue
+		}
+		im.track(objs[i])
+		for k, v := range pos {
+			im.ctx.Metadata()[objs[i]][k] = v
+		}
+	}
+
+	c := &fyne.Container{Objects: objs}
+	props["layout"] = layoutName
+	im.ctx.Metadata()[c] = props
+	lay, _ := guidefs.RegistryOf(im.ctx).Layout(layoutName)
+	c.Layout = lay.Create(c, im.ctx)
+	im.created = append(im.created, c)
+	return c, true, nil
+}
+
+// layoutOf returns the layout name for the first argument of `container.New`, setting any layout properties.
+// For a container without layout the position and size of each child is returned as well.
+func (im *goImporter) layoutOf(e ast.Expr, props map[string]string) (string, map[int]map[string]string, error) {
+	call, ok := e.(*ast.CallExpr)
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported layout %s", im.source(e))
+	}
+
+	name := im.source(call.Fun)
+	switch name {
+	case "wrapLayout":
+		return "WithoutLayout", im.childPositions(call), nil
+	case "layout.NewGridWrapLayout":
+		if len(call.Args) != 1 {
+			break
+		}
+		v, err := im.eval(call.Args[0], goTypes["fyne.Size"])
+		if err != nil {
+			return "", nil, err
+		}
+		size := v.Interface().(fyne.Size)
+		props["width"] = formatFloat(float64(size.Width))
+		props["height"] = formatFloat(float64(size.Height))
+		return "GridWrap", nil, nil
+	case "layout.NewCustomPaddedLayout":
+		if len(call.Args) != 4 {
+			break
+		}
+		for i, key := range []string{"top", "bottom", "left", "right"} {
+			props[key] = im.formatNumber(call.Args[i])
+		}
+		return "CustomPadded", nil, nil
+	case "layout.NewGridLayoutWithColumns", "layout.NewGridLayoutWithRows":
+		if len(call.Args) != 1 {
+			break
+		}
+		props["grid_type"] = strings.TrimPrefix(name, "layout.NewGridLayoutWith")
+		props["count"] = im.formatNumber(call.Args[0])
+		return "Grid", nil, nil
+	default:
+		l := strings.TrimSuffix(strings.TrimPrefix(name, "layout.New"), "Layout")
+		if info, ok := guidefs.RegistryOf(im.ctx).Layout(l); ok && len(info.Properties) == 0 && len(call.Args) == 0 {
+			return l, nil, nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("unsupported layout %s", name)
+}
+
+// childPositions reads the `objs[0].Resize(...)` and `objs[0].Move(...)` lines of a container without layout.
+func (im *goImporter) childPositions(call *ast.CallExpr) map[int]map[string]string {
+	positions := make(map[int]map[string]string)
+	if len(call.Args) == 0 {
+		return positions
+	}
+	fn, ok := call.Args[0].(*ast.FuncLit)
+	if !ok {
+		return positions
+	}
+
+	for _, stmt := range fn.Body.List {
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		move, ok := expr.X.(*ast.CallExpr)
+		if !ok || len(move.Args) != 1 {
+			continue
+		}
+		sel, ok := move.Fun.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+		index, ok := sel.X.(*ast.IndexExpr)
+		if !ok {
+			continue
+		}
+		i, err := strconv.Atoi(im.source(index.Index))
+		if err != nil {
+			continue
+		}
+		if positions[i] == nil {
+			positions[i] = make(map[string]string)
+		}
+
+		switch sel.Sel.Name {
+		case "Resize":
+			if v, err := im.eval(move.Args[0], goTypes["fyne.Size"]); err == nil {
+				size := v.Interface().(fyne.Size)
+				positions[i]["width"] = formatFloat(float64(size.Width))
+				positions[i]["height"] = formatFloat(float64(size.Height))
+			}
+		case "Move":
+			if v, err := im.eval(move.Args[0], goTypes["fyne.Position"]); err == nil {
+				pos := v.Interface().(fyne.Position)
+				positions[i]["x"] = formatFloat(float64(pos.X))
+				positions[i]["y"] = formatFloat(float64(pos.Y))
+			}
+		}
+	}
+	return positions
+}
+
+// themeOverride reads a theme override, where the theme is loaded from JSON inside a function literal.
+func (im *goImporter) themeOverride(x *ast.CallExpr) ([]reflect.Value, error) {
+	if len(x.Args) != 2 {
+		return nil, errors.New("container.NewThemeOverride needs 2 arguments")
+	}
+	v, err := im.eval(x.Args[0], canvasObjectType)
+	if err != nil {
+		return nil, err
+	}
+	content, _ := v.Interface().(fyne.CanvasObject)
+
+	data := "{}"
+	ast.Inspect(x.Args[1], func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if s, err := strconv.Unquote(lit.Value); err == nil {
+				data = s
+			}
+			return false
+		}
+		return true
+	})
+
+	th, err := theme.FromJSONWithFallback(data, guidefs.ThemeOf(im.ctx))
+	if err != nil {
+		im.report(x.Args[1].Pos(), "*container.ThemeOverride", SeverityWarning, "theme decode error: "+err.Error())
+	}
+
+	obj := container.NewThemeOverride(content, th)
+	im.track(obj)
+	im.ctx.Metadata()[obj]["data"] = data
+	return []reflect.Value{reflect.ValueOf(obj)}, nil
+}
+
+func (im *goImporter) composite(lit *ast.CompositeLit, t reflect.Type) (reflect.Value, error) {
+	if t == nil {
+		return reflect.Value{}, fmt.Errorf("unknown type %s", im.source(lit.Type))
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		v := reflect.New(t).Elem()
+		for i, el := range lit.Elts {
+			var field reflect.Value
+			value := el
+			if kv, ok := el.(*ast.KeyValueExpr); ok {
+				key, ok := kv.Key.(*ast.Ident)
+				if !ok {
+					return v, errors.New("unsupported field key")
+				}
+				field = v.FieldByName(key.Name)
+				value = kv.Value
+			} else if i < t.NumField() {
+				field = v.Field(i)
+			}
+			if !field.IsValid() || !field.CanSet() {
+				return v, fmt.Errorf("unknown field in %s", t.String())
+			}
+
+			val, err := im.eval(value, field.Type())
+			if err != nil {
+				return v, err
+			}
+			field.Set(val)
+		}
+		return v, nil
+	case reflect.Slice:
+		v := reflect.MakeSlice(t, 0, len(lit.Elts))
+		for _, el := range lit.Elts {
+			val, err := im.eval(el, t.Elem())
+			if err != nil {
+				return v, err
+			}
+			v = reflect.Append(v, val)
+		}
+		return v, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("unsupported literal of type %s", t.String())
+}
+
+func (im *goImporter) resolveType(e ast.Expr) reflect.Type {
+	switch x := e.(type) {
+	case *ast.Ident:
+		return goBasicTypes[x.Name]
+	case *ast.SelectorExpr:
+		name := im.source(x)
+		if t, ok := goTypes[name]; ok {
+			return t
+		}
+		return im.registeredType(name)
+	case *ast.StarExpr:
+		if t := im.resolveType(x.X); t != nil {
+			return reflect.PtrTo(t)
+		}
+	case *ast.ArrayType:
+		if x.Len != nil {
+			return nil
+		}
+		if t := im.resolveType(x.Elt); t != nil {
+			return reflect.SliceOf(t)
+		}
+	case *ast.ParenExpr:
+		return im.resolveType(x.X)
+	}
+	return nil
+}
+
+// registeredType returns the struct type of a registered object, such as `widget.Label`.
+func (im *goImporter) registeredType(name string) reflect.Type {
+	info := guidefs.RegistryOf(im.ctx).Lookup("*" + name)
+	if info == nil || info.Create == nil {
+		return nil
+	}
+	t := reflect.TypeOf(info.Create(im.ctx))
+	if t.Kind() != reflect.Ptr || t.Elem().String() != name {
+		return nil
+	}
+	return t.Elem()
+}
+
+// track makes sure that an object has metadata, as the code generators expect.
+func (im *goImporter) track(obj fyne.CanvasObject) {
+	if im.ctx.Metadata()[obj] != nil {
+		return
+	}
+	im.ctx.Metadata()[obj] = make(map[string]string)
+	im.created = append(im.created, obj)
+}
+
+// finish adds the metadata that is implied by the state of imported objects.
+func (im *goImporter) finish() {
+	for _, obj := range im.created {
+		if form, ok := obj.(*widget.Form); ok && form.OnSubmit == nil && form.OnCancel == nil {
+			im.ctx.Metadata()[obj]["hideButtons"] = "true"
+		}
+	}
+}
+
+func (im *goImporter) formatNumber(e ast.Expr) string {
+	v, err := im.eval(e, goBasicTypes["float64"])
+	if err != nil {
+		return ""
+	}
+	return formatFloat(v.Float())
+}
+
+func (im *goImporter) source(e ast.Node) string {
+	buf := &bytes.Buffer{}
+	_ = printer.Fprint(buf, im.fset, e)
+	return buf.String()
+}
+
+func basicLiteral(lit *ast.BasicLit) (reflect.Value, error) {
+	switch lit.Kind {
+	case token.STRING, token.CHAR:
+		s, err := strconv.Unquote(lit.Value)
+		if lit.Kind == token.CHAR {
+			return reflect.ValueOf([]rune(s)[0]), err
+		}
+		return reflect.ValueOf(s), err
+	case token.INT:
+		i, err := strconv.ParseInt(lit.Value, 0, 64)
+		return reflect.ValueOf(int(i)), err
+	case token.FLOAT:
+		f, err := strconv.ParseFloat(lit.Value, 64)
+		return reflect.ValueOf(f), err
+	}
+	return reflect.Value{}, errors.New("unsupported literal " + lit.Value)
+}
+
+// convertValue converts a value to the wanted type, as Go would for an assignment or untyped constant.
+func convertValue(v reflect.Value, want reflect.Type) (reflect.Value, error) {
+	if want == nil || !v.IsValid() {
+		return v, nil
+	}
+	if v.Kind() == reflect.Interface && !v.IsNil() && !v.Type().AssignableTo(want) {
+		v = v.Elem()
+	}
+	if v.Type().AssignableTo(want) {
+		return v, nil
+	}
+	if want.Kind() != reflect.Interface && v.Kind() != reflect.Interface && v.Type().ConvertibleTo(want) {
+		return v.Convert(want), nil
+	}
+	if v.Kind() == reflect.Interface && v.IsNil() {
+		return reflect.Zero(want), nil
+	}
+
+	return v, fmt.Errorf("cannot use %s as %s", v.Type(), want)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// addressOf returns a pointer to a value, copying it to a new variable if it cannot be addressed,
+// such as the slices and maps made by a composite literal.
+func addressOf(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v.Addr()
+	}
+
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr
+}
+
+// isNilValue returns true if a value is a nil pointer or interface, such as a typed nil object.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func:
+		return v.IsNil()
+	}
+	return !v.IsValid()
+}
+
+func isNil(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "nil"
+}
+
+func tabLocationName(e ast.Expr) string {
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	return strings.TrimPrefix(sel.Sel.Name, "TabLocation")
+}
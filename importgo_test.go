@@ -0,0 +1,185 @@
+package refyne
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportGo(t *testing.T) {
+	code := `package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+type whatGui struct {
+	win fyne.Window
+
+	bubu *widget.Button
+	coco *fyne.Container
+}
+
+func newWhatGUI() *whatGui {
+	return &whatGui{}
+}
+
+func (g *whatGui) makeUI() fyne.CanvasObject {
+	g.bubu = widget.NewButton("Foo", nil)
+	g.coco = container.NewVBox(
+		widget.NewLabel("Hello"),
+		g.bubu)
+
+	g.bubu.OnTapped = g.coco.Hide
+	g.bubu.Importance = 2
+
+	return g.coco
+}
+`
+	ctx := DefaultContext()
+	obj, err := ImportGo(strings.NewReader(code), ctx)
+	require.NoError(t, err)
+
+	c, ok := obj.(*fyne.Container)
+	require.True(t, ok)
+	require.Len(t, c.Objects, 2)
+	assert.Equal(t, "coco", ctx.Metadata()[c]["name"])
+	assert.Equal(t, "VBox", ctx.Metadata()[c]["layout"])
+	assert.Equal(t, "Hello", c.Objects[0].(*widget.Label).Text)
+
+	b := c.Objects[1].(*widget.Button)
+	assert.Equal(t, "Foo", b.Text)
+	assert.Equal(t, widget.Importance(2), b.Importance)
+	assert.Equal(t, "bubu", ctx.Metadata()[b]["name"])
+	assert.Equal(t, "g.coco.Hide", ctx.Metadata()[b]["OnTapped"])
+}
+
+func TestImportGoRoundTrip(t *testing.T) {
+	ctx := DefaultContext()
+	meta := ctx.Metadata()
+
+	btn := widget.NewButtonWithIcon("Go", theme.HomeIcon(), nil)
+	btn.Importance = widget.DangerImportance
+	meta[btn] = map[string]string{"name": "goButton", "OnTapped": "g.win.Close"}
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("Name")
+	meta[entry] = map[string]string{"name": "nameEntry"}
+
+	top := widget.NewLabel("Top")
+	border := container.NewBorder(top, nil, nil, nil, entry)
+	meta[border] = map[string]string{"layout": "Border", "top": "1"}
+	grid := container.NewGridWithRows(3, btn, widget.NewCheck("Check", nil))
+	meta[grid] = map[string]string{"layout": "Grid", "grid_type": "Rows", "count": "3"}
+	split := container.NewHSplit(border, grid)
+	split.Offset = 0.25
+
+	tabs := container.NewAppTabs(container.NewTabItem("One", split),
+		container.NewTabItemWithIcon("Two", theme.InfoIcon(), container.NewScroll(widget.NewLabel("Scrolled"))))
+	meta[tabs] = map[string]string{"name": "tabs", "location": "Bottom"}
+
+	var first bytes.Buffer
+	require.NoError(t, ExportGo(tabs, ctx, "round", &first))
+
+	ctx2 := DefaultContext()
+	obj, err := ImportGo(bytes.NewReader(first.Bytes()), ctx2)
+	require.NoError(t, err)
+
+	imported, ok := obj.(*container.AppTabs)
+	require.True(t, ok)
+	require.Len(t, imported.Items, 2)
+	assert.Equal(t, "Bottom", ctx2.Metadata()[imported]["location"])
+
+	var second bytes.Buffer
+	require.NoError(t, ExportGo(obj, ctx2, "round", &second))
+	assert.Equal(t, first.String(), second.String())
+}
+
+func TestImportGoErrors(t *testing.T) {
+	_, err := ImportGo(strings.NewReader("package main\n\nfunc main() {}\n"), DefaultContext())
+	assert.Error(t, err)
+
+	code := `package main
+
+func (g *gui) makeUI() fyne.CanvasObject {
+	g.label = widget.NewLabel("Hi")
+	g.label.Unknown = 5
+	doSomething()
+
+	return container.NewVBox(g.label)
+}
+`
+	obj, err := ImportGo(strings.NewReader(code), DefaultContext())
+	require.NotNil(t, obj)
+	var decErr *DecodeError
+	require.ErrorAs(t, err, &decErr)
+	assert.False(t, decErr.HasErrors())
+	require.Len(t, decErr.Issues, 2)
+	assert.Equal(t, "5:10", decErr.Issues[0].Path)
+	assert.Equal(t, "6:2", decErr.Issues[1].Path)
+}
+
+func TestImportGoCallWithoutResult(t *testing.T) {
+	code := `package main
+
+func (g *gui) makeUI() fyne.CanvasObject {
+	g.label = widget.NewLabel("Hi")
+	g.x = g.label.Refresh()
+	g.label.Text = g.label.Refresh()
+	g.label.Refresh().Hide()
+	y := g.label.Refresh()
+	widget.NewLabel(g.label.Refresh())
+
+	return container.NewVBox(g.label)
+}
+`
+	obj, err := ImportGo(strings.NewReader(code), DefaultContext())
+	require.NotNil(t, obj)
+	var decErr *DecodeError
+	require.ErrorAs(t, err, &decErr)
+	require.Len(t, decErr.Issues, 5)
+	for _, issue := range decErr.Issues {
+		assert.Contains(t, issue.Message, "g.label.Refresh() does not have a value")
+	}
+}
+
+func TestImportGoAddressOfSlice(t *testing.T) {
+	code := `package main
+
+func (g *gui) makeUI() fyne.CanvasObject {
+	opts := &[]string{"a", "b"}
+	g.pick = widget.NewSelect(*opts, nil)
+
+	return container.NewVBox(g.pick)
+}
+`
+	var obj fyne.CanvasObject
+	var err error
+	require.NotPanics(t, func() {
+		obj, err = ImportGo(strings.NewReader(code), DefaultContext())
+	})
+	require.NoError(t, err)
+	pick := obj.(*fyne.Container).Objects[0].(*widget.Select)
+	assert.Equal(t, []string{"a", "b"}, pick.Options)
+}
+
+func TestImportGoTypedNil(t *testing.T) {
+	code := `package main
+
+func (g *gui) makeUI() fyne.CanvasObject {
+	return (*widget.Label)(nil)
+}
+`
+	obj, err := ImportGo(strings.NewReader(code), DefaultContext())
+	assert.Error(t, err)
+	assert.Nil(t, obj)
+}
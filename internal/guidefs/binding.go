@@ -0,0 +1,24 @@
+package guidefs
+
+import (
+	"fyne.io/fyne/v2"
+)
+
+// BindingTypes lists the widgets that can be bound to data, and the type of binding that each one uses.
+// A widget is bound by setting the "Data" metadata to the name of a binding field on the GUI struct.
+var BindingTypes = map[string]string{
+	"*widget.Check":       "Bool",
+	"*widget.Entry":       "String",
+	"*widget.Label":       "String",
+	"*widget.List":        "StringList",
+	"*widget.ProgressBar": "Float",
+	"*widget.Slider":      "Float",
+}
+
+// Binding returns the name of the binding field that the object is bound to, or "" if it is not bound.
+func Binding(obj fyne.CanvasObject, c Context) string {
+	if _, ok := BindingTypes[RegistryOf(c).TypeName(obj)]; !ok {
+		return ""
+	}
+	return c.Metadata()[obj]["Data"]
+}
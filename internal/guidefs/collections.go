@@ -0,0 +1,650 @@
+package guidefs
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// The collection settings are stored in the metadata, so that they are saved with the object and can be
+// read by the callbacks of the collection whenever it is refreshed.
+const (
+	defaultListLength   = 5
+	defaultTableRows    = 3
+	defaultTableColumns = 3
+	defaultTemplate     = "*widget.Label"
+
+	listPlaceholder  = "Template Object"
+	tablePlaceholder = "Cell 000, 000"
+)
+
+const defaultTreeItems = `A
+	B
+		C
+			abc
+	D
+		E
+			F
+				adef
+			G
+				adeg
+	H
+		I
+			ahi
+	J
+	L
+	O
+		ao
+	P
+		Q
+			R
+				apqr
+	S
+		T
+			U
+				astu
+	V
+		W
+			X
+				Y
+					Z
+						avwxyz`
+
+type textSetter interface {
+	SetText(string)
+}
+
+type stringBinder interface {
+	Bind(binding.String)
+}
+
+func initCollections() map[string]WidgetInfo {
+	return map[string]WidgetInfo{
+		"*widget.List":  initListCollection(),
+		"*widget.Table": initTableCollection(),
+		"*widget.Tree":  initTreeCollection(),
+	}
+}
+
+func initListCollection() WidgetInfo {
+	return WidgetInfo{
+		Name: "List",
+		Create: func(c Context) fyne.CanvasObject {
+			l := &widget.List{}
+			l.Length = func() int {
+				return intSetting(c.Metadata()[l], "length", defaultListLength)
+			}
+			l.CreateItem = func() fyne.CanvasObject {
+				return collectionTemplate(c.Metadata()[l], listPlaceholder, c)
+			}
+			l.UpdateItem = func(id widget.ListItemID, item fyne.CanvasObject) {
+				if text, ok := item.(textSetter); ok {
+					text.SetText(fmt.Sprintf("Item %d", id+1))
+				}
+			}
+			l.ExtendBaseWidget(l)
+			return l
+		},
+		Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
+			l := obj.(*widget.List)
+			props := collectionProps(obj, c)
+
+			length := newCountEntry(props, "length", defaultListLength, func() {
+				l.Refresh()
+				onchanged()
+			})
+			data := widget.NewEntry()
+			data.SetPlaceHolder("(Not Bound)")
+			data.SetText(props["Data"])
+			data.OnChanged = func(s string) {
+				setSetting(props, "Data", s, "")
+				onchanged()
+			}
+			template := newTemplateSelect(props, c, func() {
+				reloadList(l)
+				onchanged()
+			})
+
+			return []*widget.FormItem{
+				widget.NewFormItem("Length", length),
+				widget.NewFormItem("Data", data),
+				widget.NewFormItem("Template", template),
+			}
+		},
+		Gostring: func(obj fyne.CanvasObject, c Context, defs map[string]string) string {
+			props := c.Metadata()[obj]
+			template := collectionTemplate(props, listPlaceholder, c)
+			create := templateCode(template, c)
+			if data := Binding(obj, c); data != "" {
+				update := ""
+				if _, ok := template.(stringBinder); ok {
+					update = "\n\t\t\t\tobj.(" + RegistryOf(c).TypeName(template) + ").Bind(item.(binding.String))\n\t\t\t"
+				}
+				return widgetRef(obj, c, defs,
+					`widget.NewListWithData(g.`+data+`, func() fyne.CanvasObject {
+				`+create+`
+			}, func(item binding.DataItem, obj fyne.CanvasObject) {`+update+`})`)
+			}
+
+			update := ""
+			if _, ok := template.(textSetter); ok {
+				update = "\n\t\t\t\titem.(" + RegistryOf(c).TypeName(template) + ").SetText(fmt.Sprintf(\"Item %d\", id+1))\n\t\t\t"
+			}
+			return widgetRef(obj, c, defs,
+				fmt.Sprintf(`widget.NewList(func() int {
+				return %d
+			}, func() fyne.CanvasObject {
+				%s
+			}, func(id widget.ListItemID, item fyne.CanvasObject) {%s})`,
+					intSetting(props, "length", defaultListLength), create, update))
+		},
+		Packages: func(obj fyne.CanvasObject, c Context) []string {
+			template := collectionTemplate(c.Metadata()[obj], listPlaceholder, c)
+			pkgs := templatePackages(template, c)
+			if Binding(obj, c) != "" {
+				return append(pkgs, "fyne.io/fyne/v2/data/binding")
+			}
+			if _, ok := template.(textSetter); ok {
+				return append(pkgs, "fmt")
+			}
+			return pkgs
+		},
+	}
+}
+
+func initTableCollection() WidgetInfo {
+	return WidgetInfo{
+		Name: "Table",
+		Create: func(c Context) fyne.CanvasObject {
+			t := &widget.Table{}
+			t.Length = func() (int, int) {
+				props := c.Metadata()[t]
+				return intSetting(props, "rows", defaultTableRows), intSetting(props, "columns", defaultTableColumns)
+			}
+			t.CreateCell = func() fyne.CanvasObject {
+				return collectionTemplate(c.Metadata()[t], tablePlaceholder, c)
+			}
+			t.UpdateCell = func(id widget.TableCellID, cell fyne.CanvasObject) {
+				if text, ok := cell.(textSetter); ok {
+					text.SetText(fmt.Sprintf("Cell %d, %d", id.Row+1, id.Col+1))
+				}
+			}
+			t.CreateHeader = func() fyne.CanvasObject {
+				return widget.NewLabel("")
+			}
+			t.UpdateHeader = func(id widget.TableCellID, template fyne.CanvasObject) {
+				headers := listSetting(c.Metadata()[t], "headers")
+				text := ""
+				if id.Col >= 0 && id.Col < len(headers) {
+					text = headers[id.Col]
+				}
+				template.(*widget.Label).SetText(text)
+			}
+			t.ExtendBaseWidget(t)
+			applyColumnWidths(t, c.Metadata()[t])
+			return t
+		},
+		Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
+			t := obj.(*widget.Table)
+			props := collectionProps(obj, c)
+
+			changed := func() {
+				applyColumnWidths(t, props)
+				t.Refresh()
+				onchanged()
+			}
+			rows := newCountEntry(props, "rows", defaultTableRows, changed)
+			cols := newCountEntry(props, "columns", defaultTableColumns, changed)
+			template := newTemplateSelect(props, c, func() {
+				reloadTable(t)
+				applyColumnWidths(t, props)
+				onchanged()
+			})
+
+			headers := widget.NewEntry()
+			headers.SetPlaceHolder("Name, Value")
+			headers.SetText(props["headers"])
+			headers.OnChanged = func(s string) {
+				setSetting(props, "headers", s, "")
+				t.ShowHeaderRow = len(listSetting(props, "headers")) > 0
+				changed()
+			}
+			widths := widget.NewEntry()
+			widths.SetPlaceHolder("120, 80")
+			widths.SetText(props["widths"])
+			widths.Validator = func(s string) error {
+				_, err := parseWidths(s)
+				return err
+			}
+			widths.OnChanged = func(s string) {
+				if _, err := parseWidths(s); err != nil {
+					return
+				}
+				setSetting(props, "widths", s, "")
+				changed()
+			}
+
+			return []*widget.FormItem{
+				widget.NewFormItem("Rows", rows),
+				widget.NewFormItem("Columns", cols),
+				widget.NewFormItem("Template", template),
+				widget.NewFormItem("Headers", headers),
+				widget.NewFormItem("Widths", widths),
+			}
+		},
+		Gostring: func(obj fyne.CanvasObject, c Context, defs map[string]string) string {
+			t := obj.(*widget.Table)
+			props := c.Metadata()[obj]
+			rows := intSetting(props, "rows", defaultTableRows)
+			cols := intSetting(props, "columns", defaultTableColumns)
+
+			attrs := c.Attrs()[obj]
+			if headers := listSetting(props, "headers"); len(headers) > 0 {
+				quoted := make([]string, cols)
+				for i := range quoted {
+					header := ""
+					if i < len(headers) {
+						header = headers[i]
+					}
+					quoted[i] = strconv.Quote(header)
+				}
+				attrs = append(attrs, "ShowHeaderRow = true",
+					`CreateHeader = func() fyne.CanvasObject {
+				return widget.NewLabel("")
+			}`,
+					`UpdateHeader = func(id widget.TableCellID, template fyne.CanvasObject) {
+				template.(*widget.Label).SetText([]string{`+strings.Join(quoted, ", ")+`}[id.Col])
+			}`)
+			} else if t.ShowHeaderRow {
+				attrs = append(attrs, "ShowHeaderRow = true")
+			}
+			widths, _ := parseWidths(props["widths"])
+			for i, w := range widths {
+				attrs = append(attrs, fmt.Sprintf("SetColumnWidth(%d, %s)", i, strconv.FormatFloat(float64(w), 'f', -1, 32)))
+			}
+			c.Attrs()[obj] = attrs
+
+			template := collectionTemplate(props, tablePlaceholder, c)
+			update := ""
+			if _, ok := template.(textSetter); ok {
+				update = "\n\t\t\t\tcell.(" + RegistryOf(c).TypeName(template) +
+					").SetText(fmt.Sprintf(\"Cell %d, %d\", id.Row+1, id.Col+1))\n\t\t\t"
+			}
+			return widgetRef(obj, c, defs,
+				fmt.Sprintf(`widget.NewTable(func() (int, int) {
+				return %d, %d
+			}, func() fyne.CanvasObject {
+				%s
+			}, func(id widget.TableCellID, cell fyne.CanvasObject) {%s})`, rows, cols, templateCode(template, c), update))
+		},
+		Packages: func(obj fyne.CanvasObject, c Context) []string {
+			template := collectionTemplate(c.Metadata()[obj], tablePlaceholder, c)
+			pkgs := templatePackages(template, c)
+			if _, ok := template.(textSetter); ok {
+				return append(pkgs, "fmt")
+			}
+			return pkgs
+		},
+	}
+}
+
+func initTreeCollection() WidgetInfo {
+	return WidgetInfo{
+		Name: "Tree",
+		Create: func(c Context) fyne.CanvasObject {
+			t := &widget.Tree{}
+			items := treeItemCache{}
+			t.ChildUIDs = func(id widget.TreeNodeID) []widget.TreeNodeID {
+				return items.get(c.Metadata()[t])[id]
+			}
+			t.IsBranch = func(id widget.TreeNodeID) bool {
+				_, ok := items.get(c.Metadata()[t])[id]
+				return ok
+			}
+			t.CreateNode = func(bool) fyne.CanvasObject {
+				return widget.NewLabel("Template Object")
+			}
+			t.UpdateNode = func(id widget.TreeNodeID, _ bool, node fyne.CanvasObject) {
+				node.(*widget.Label).SetText(id)
+			}
+			t.ExtendBaseWidget(t)
+			return t
+		},
+		Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
+			t := obj.(*widget.Tree)
+			props := collectionProps(obj, c)
+
+			items := widget.NewMultiLineEntry()
+			items.SetPlaceHolder("Root\n\tChild")
+			items.SetText(treeSetting(props))
+			items.Validator = func(s string) error {
+				_, err := parseTreeItems(s)
+				return err
+			}
+			items.OnChanged = func(s string) {
+				if _, err := parseTreeItems(s); err != nil {
+					return
+				}
+				setSetting(props, "items", s, defaultTreeItems)
+				t.Refresh()
+				onchanged()
+			}
+
+			return []*widget.FormItem{
+				widget.NewFormItem("Items", items),
+			}
+		},
+		Gostring: func(obj fyne.CanvasObject, c Context, defs map[string]string) string {
+			data, err := parseTreeItems(treeSetting(c.Metadata()[obj]))
+			if err != nil {
+				fyne.LogError("Invalid tree items", err)
+			}
+			ids := make([]string, 0, len(data))
+			for id := range data {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+
+			var code strings.Builder
+			code.WriteString("widget.NewTreeWithStrings(\nmap[string][]string{\n")
+			for _, id := range ids {
+				children := make([]string, len(data[id]))
+				for i, child := range data[id] {
+					children[i] = strconv.Quote(child)
+				}
+				fmt.Fprintf(&code, "\t%s: {%s},\n", strconv.Quote(id), strings.Join(children, ", "))
+			}
+			code.WriteString("})")
+			return widgetRef(obj, c, defs, code.String())
+		},
+	}
+}
+
+// collectionProps returns the metadata for a collection, creating it if required so that settings can be stored.
+func collectionProps(obj fyne.CanvasObject, c Context) map[string]string {
+	props := c.Metadata()[obj]
+	if props == nil {
+		props = make(map[string]string)
+		c.Metadata()[obj] = props
+	}
+	return props
+}
+
+// collectionTemplate creates the template object for a collection, showing the placeholder text if it has any.
+func collectionTemplate(props map[string]string, placeholder string, c Context) fyne.CanvasObject {
+	reg := RegistryOf(c)
+	info, ok := reg.Widget(props["template"])
+	if !ok {
+		info, _ = reg.Widget(defaultTemplate)
+	}
+
+	obj := info.Create(c)
+	if text, ok := obj.(textSetter); ok {
+		text.SetText(placeholder)
+	}
+	return obj
+}
+
+// templateCode returns the body of a function that creates the given template object.
+func templateCode(template fyne.CanvasObject, parent Context) string {
+	c := &templateContext{
+		meta:  map[fyne.CanvasObject]map[string]string{template: {"name": "item", "name-is-generated": "1"}},
+		attrs: make(map[fyne.CanvasObject][]string),
+		res:   ResourcesOf(parent),
+		reg:   RegistryOf(parent),
+	}
+	defs := make(map[string]string)
+	GoString(c.reg.TypeName(template), template, c, defs)
+
+	attrs := c.attrs[template]
+	if len(attrs) == 0 {
+		return "return " + defs["item"]
+	}
+
+	lines := []string{"item := " + defs["item"]}
+	for _, attr := range attrs {
+		lines = append(lines, "item."+attr)
+	}
+	return strings.Join(append(lines, "return item"), "\n")
+}
+
+func templatePackages(template fyne.CanvasObject, parent Context) []string {
+	reg := RegistryOf(parent)
+	if info := reg.Lookup(TypeName(template)); info != nil && info.Packages != nil {
+		return append(info.Packages(template, &templateContext{reg: reg}), "widget")
+	}
+	return []string{"widget"}
+}
+
+func newCountEntry(props map[string]string, key string, fallback int, changed func()) *widget.Entry {
+	count := widget.NewEntry()
+	count.SetText(strconv.Itoa(intSetting(props, key, fallback)))
+	count.Validator = func(s string) error {
+		if i, err := strconv.Atoi(s); err != nil || i < 0 {
+			return errors.New("invalid count")
+		}
+		return nil
+	}
+	count.OnChanged = func(s string) {
+		i, err := strconv.Atoi(s)
+		if err != nil || i < 0 {
+			return
+		}
+
+		setSetting(props, key, s, strconv.Itoa(fallback))
+		changed()
+	}
+	return count
+}
+
+func newTemplateSelect(props map[string]string, c Context, changed func()) *widget.Select {
+	reg := RegistryOf(c)
+	names := append([]string(nil), reg.WidgetNames()...)
+	sort.Strings(names)
+
+	template := widget.NewSelect(names, nil)
+	template.SetSelected(defaultTemplate)
+	if _, ok := reg.Widget(props["template"]); ok {
+		template.SetSelected(props["template"])
+	}
+	template.OnChanged = func(s string) {
+		setSetting(props, "template", s, defaultTemplate)
+		changed()
+	}
+	return template
+}
+
+// reloadList discards the item objects of a list, so that they are created again from a new template.
+func reloadList(l *widget.List) {
+	length := l.Length
+	l.Length = func() int {
+		return 0
+	}
+	l.Refresh()
+	l.Length = length
+	l.Refresh()
+}
+
+// reloadTable discards the cell objects of a table, so that they are created again from a new template.
+func reloadTable(t *widget.Table) {
+	length := t.Length
+	t.Length = func() (int, int) {
+		return 0, 0
+	}
+	t.Refresh()
+	t.Length = length
+	t.Refresh()
+}
+
+// ApplyColumnWidths sets the column widths of a table from the "widths" setting in its metadata.
+// It is called when the metadata is loaded or changed, and does nothing for other objects.
+func ApplyColumnWidths(obj fyne.CanvasObject, c Context) {
+	if t, ok := obj.(*widget.Table); ok {
+		applyColumnWidths(t, c.Metadata()[t])
+	}
+}
+
+// applyColumnWidths sets the column widths of a table from its settings, which only refreshes if they changed.
+// A table cannot forget a width once it is set, so the columns without one are set to the width of the cell template,
+// which is the size that the table would otherwise use.
+func applyColumnWidths(t *widget.Table, props map[string]string) {
+	widths, _ := parseWidths(props["widths"])
+	cols := intSetting(props, "columns", defaultTableColumns)
+	cell := float32(0)
+	if len(widths) < cols && t.CreateCell != nil {
+		cell = t.CreateCell().MinSize().Width
+		if (t.ShowHeaderRow || t.ShowHeaderColumn) && t.CreateHeader != nil {
+			cell = fyne.Max(cell, t.CreateHeader().MinSize().Width)
+		}
+	}
+
+	for i := 0; i < cols || i < len(widths); i++ {
+		if i < len(widths) {
+			t.SetColumnWidth(i, widths[i])
+		} else {
+			t.SetColumnWidth(i, cell)
+		}
+	}
+}
+
+func intSetting(props map[string]string, key string, fallback int) int {
+	if i, err := strconv.Atoi(props[key]); err == nil && i >= 0 {
+		return i
+	}
+	return fallback
+}
+
+// listSetting returns the items of a comma separated setting.
+func listSetting(props map[string]string, key string) []string {
+	if strings.TrimSpace(props[key]) == "" {
+		return nil
+	}
+
+	items := strings.Split(props[key], ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
+
+// setSetting stores a value in the metadata, removing it if it matches the default.
+func setSetting(props map[string]string, key, value, fallback string) {
+	if value == fallback {
+		delete(props, key)
+		return
+	}
+	props[key] = value
+}
+
+func parseWidths(s string) ([]float32, error) {
+	items := listSetting(map[string]string{"widths": s}, "widths")
+	widths := make([]float32, len(items))
+	for i, item := range items {
+		f, err := strconv.ParseFloat(item, 32)
+		if err != nil || f < 0 {
+			return nil, fmt.Errorf("invalid width %q", item)
+		}
+		widths[i] = float32(f)
+	}
+	return widths, nil
+}
+
+func treeSetting(props map[string]string) string {
+	if items, ok := props["items"]; ok {
+		return items
+	}
+	return defaultTreeItems
+}
+
+// parseTreeItems reads an outline, with one node per line and children indented below their parent,
+// into the map of child IDs that is used by `widget.NewTreeWithStrings`.
+func parseTreeItems(outline string) (map[string][]string, error) {
+	type level struct {
+		indent int
+		id     string
+	}
+
+	data := make(map[string][]string)
+	stack := []level{{indent: -1}}
+	seen := make(map[string]bool)
+	for i, line := range strings.Split(outline, "\n") {
+		id := strings.TrimSpace(line)
+		if id == "" {
+			continue
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("line %d: %q is already in the tree", i+1, id)
+		}
+		seen[id] = true
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		for stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].id
+		data[parent] = append(data[parent], id)
+		stack = append(stack, level{indent: indent, id: id})
+	}
+	return data, nil
+}
+
+// treeItemCache holds the parsed tree items so that the outline is only read again when it changes.
+type treeItemCache struct {
+	outline string
+	data    map[string][]string
+}
+
+func (c *treeItemCache) get(props map[string]string) map[string][]string {
+	outline := treeSetting(props)
+	if c.data != nil && outline == c.outline {
+		return c.data
+	}
+
+	if data, err := parseTreeItems(outline); err == nil {
+		c.outline, c.data = outline, data
+	}
+	return c.data
+}
+
+// templateContext is used to generate code for template objects, which are not part of the object tree.
+type templateContext struct {
+	meta  map[fyne.CanvasObject]map[string]string
+	attrs map[fyne.CanvasObject][]string
+	res   map[string]Resource
+	reg   *Registry
+}
+
+func (c *templateContext) Metadata() map[fyne.CanvasObject]map[string]string {
+	return c.meta
+}
+
+func (c *templateContext) Attrs() map[fyne.CanvasObject][]string {
+	return c.attrs
+}
+
+func (c *templateContext) Theme() fyne.Theme {
+	return theme.Current()
+}
+
+func (c *templateContext) Root() fyne.CanvasObject {
+	return nil
+}
+
+func (c *templateContext) Resources() map[string]Resource {
+	return c.res
+}
+
+func (c *templateContext) Registry() *Registry {
+	return c.reg
+}
+
+func (c *templateContext) History() *History {
+	return nil
+}
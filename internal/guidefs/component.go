@@ -0,0 +1,102 @@
+package guidefs
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	// ComponentKey is the metadata key of an object that was loaded from a component reference.
+	// Its value is the path or name of the component, which is saved instead of the objects.
+	ComponentKey = "component"
+	// ComponentOverridesKey is the metadata key that holds the JSON of the field values set on
+	// named objects inside a component, keyed by object name and then field name.
+	ComponentOverridesKey = "componentOverrides"
+)
+
+// IsComponent returns true if the object is the root of a component that is loaded from a reference.
+func IsComponent(obj fyne.CanvasObject, c Context) bool {
+	return c.Metadata()[obj][ComponentKey] != ""
+}
+
+// ComponentName returns the name used for the GUI of a component, from its path or name.
+// For example "screens/main-header.gui.json" is "mainHeader".
+func ComponentName(ref string) string {
+	base := path.Base(strings.ReplaceAll(ref, "\\", "/"))
+	base = strings.TrimSuffix(strings.TrimSuffix(base, ".json"), ".gui")
+
+	name := strings.Builder{}
+	upper := false
+	for _, r := range base {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = name.Len() > 0
+			continue
+		}
+		if name.Len() == 0 && unicode.IsDigit(r) {
+			name.WriteRune('c')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+	if name.Len() == 0 {
+		return "component"
+	}
+	return name.String()
+}
+
+// ComponentConstructor returns the name of the function that creates the GUI exported for a component.
+// A component called "main" uses the default constructor name.
+func ComponentConstructor(ref string) string {
+	name := ComponentName(ref)
+	if name == "main" {
+		return "newGUI"
+	}
+	return "new" + strings.ToUpper(name[:1]) + name[1:] + "GUI"
+}
+
+// componentCode returns the Go code that creates the objects of a component using its exported GUI.
+// Overrides of string, number and boolean fields are set on the named objects before they are returned.
+func componentCode(obj fyne.CanvasObject, c Context) string {
+	props := c.Metadata()[obj]
+	constructor := ComponentConstructor(props[ComponentKey])
+
+	var overrides map[string]map[string]interface{}
+	_ = json.Unmarshal([]byte(props[ComponentOverridesKey]), &overrides)
+	var lines []string
+	for name, fields := range overrides {
+		for field, v := range fields {
+			if value, ok := overrideCode(v); ok {
+				lines = append(lines, fmt.Sprintf("comp.%s.%s = %s", name, field, value))
+			}
+		}
+	}
+	sort.Strings(lines)
+	if len(lines) == 0 {
+		return constructor + "().makeUI()"
+	}
+
+	return fmt.Sprintf("func() fyne.CanvasObject {\ncomp := %s()\nui := comp.makeUI()\n%s\nreturn ui\n}()",
+		constructor, strings.Join(lines, "\n"))
+}
+
+func overrideCode(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return strconv.Quote(val), true
+	case bool:
+		return strconv.FormatBool(val), true
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	}
+	return "", false
+}
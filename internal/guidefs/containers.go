@@ -14,7 +14,10 @@ import (
 func initContainers() {
 	Containers = map[string]WidgetInfo{
 		"*fyne.Container": {
-			Name: "Container",
+			Name:         "Container",
+			InsertChild:  insertContainerChild,
+			RemoveChild:  removeContainerChild,
+			ReplaceChild: replaceContainerChild,
 			Create: func(Context) fyne.CanvasObject {
 				return container.NewVBox()
 			},
@@ -22,11 +25,11 @@ func initContainers() {
 				props := ctx.Metadata()[obj]
 				c := obj.(*fyne.Container)
 
-				choose := widget.NewFormItem("Layout", widget.NewSelect(layoutNames, nil))
+				choose := widget.NewFormItem("Layout", widget.NewSelect(RegistryOf(ctx).LayoutNames(), nil))
 				items := []*widget.FormItem{choose}
 				ready := false
 				choose.Widget.(*widget.Select).OnChanged = func(l string) {
-					lay := Layouts[l]
+					lay, _ := RegistryOf(ctx).Layout(l)
 					props["layout"] = l
 					c.Layout = lay.Create(c, ctx)
 					c.Refresh()
@@ -53,9 +56,9 @@ func initContainers() {
 				if l == "" {
 					l = "VBox"
 				}
-				lay := Layouts[l]
-				if lay.goText != nil {
-					code := lay.goText(c, ctx, defs)
+				lay, _ := RegistryOf(ctx).Layout(l)
+				if lay.Gostring != nil {
+					code := lay.Gostring(c, ctx, defs)
 					return widgetRef(obj, ctx, defs, code)
 				}
 
@@ -102,11 +105,14 @@ func initContainers() {
 				return children
 			},
 			AddChild: func(parent, o fyne.CanvasObject) {
-				tabs := o.(*container.AppTabs)
+				tabs := parent.(*container.AppTabs)
 
 				item := container.NewTabItem("Untitled", o)
 				tabs.Append(item)
 			},
+			InsertChild:  insertTabChild,
+			RemoveChild:  removeTabChild,
+			ReplaceChild: replaceTabChild,
 			Create: func(Context) fyne.CanvasObject {
 				return container.NewAppTabs(container.NewTabItem("Untitled", container.NewStack()))
 			},
@@ -140,7 +146,7 @@ func initContainers() {
 				items[0] = widget.NewFormItem("Location", locations)
 
 				newRow := func(item *container.TabItem, i int) *widget.FormItem {
-					icon := newIconSelectorButton(item.Icon, func(i fyne.Resource) {
+					icon := newIconSelectorButton(item.Icon, c, func(i fyne.Resource) {
 						item.Icon = i
 						tabs.Refresh()
 						onchanged()
@@ -230,7 +236,7 @@ func initContainers() {
 					}
 					str.WriteString(fmt.Sprintf("container.%s(\"%s\", ", constr, c.Text))
 					if hasIcon {
-						str.WriteString("theme." + IconName(c.Icon) + "(), ")
+						str.WriteString(ResourceCode(c.Icon, ctx) + ", ")
 					}
 					writeGoStringExcluding(str, nil, ctx, defs, c.Content)
 					str.WriteString(")")
@@ -254,14 +260,13 @@ func initContainers() {
 
 				return widgetRef(obj, ctx, defs, str.String())
 			},
-			Packages: func(obj fyne.CanvasObject, _ Context) []string {
+			Packages: func(obj fyne.CanvasObject, ctx Context) []string {
 				tabs := obj.(*container.AppTabs)
+				pkgs := []string{"container"}
 				for _, c := range tabs.Items {
-					if c.Icon != nil {
-						return []string{"container", "theme"}
-					}
+					pkgs = appendPackages(pkgs, resourcePackages(c.Icon, ctx)...)
 				}
-				return []string{"container"}
+				return pkgs
 			},
 		},
 		"*container.Clip": {
@@ -275,6 +280,7 @@ func initContainers() {
 				c.Content = o
 				c.Refresh()
 			},
+			ReplaceChild: replaceContent,
 			Create: func(Context) fyne.CanvasObject {
 				return container.NewClip(container.NewStack())
 			},
@@ -306,6 +312,7 @@ func initContainers() {
 				scr.Root = o
 				scr.Refresh()
 			},
+			ReplaceChild: replaceContent,
 			Create: func(Context) fyne.CanvasObject {
 				return container.NewNavigation(container.NewStack())
 			},
@@ -351,6 +358,7 @@ func initContainers() {
 				scr.Content = o
 				scr.Refresh()
 			},
+			ReplaceChild: replaceContent,
 			Create: func(Context) fyne.CanvasObject {
 				return container.NewScroll(container.NewStack())
 			},
@@ -434,6 +442,15 @@ func initContainers() {
 				}
 				split.Refresh()
 			},
+			ReplaceChild: func(parent fyne.CanvasObject, index int, o fyne.CanvasObject, _ Context) {
+				split := parent.(*container.Split)
+				if index == 0 {
+					split.Leading = o
+				} else {
+					split.Trailing = o
+				}
+				split.Refresh()
+			},
 			Create: func(Context) fyne.CanvasObject {
 				return container.NewHSplit(container.NewStack(), container.NewStack())
 			},
@@ -480,12 +497,13 @@ func initContainers() {
 				return []fyne.CanvasObject{over.Content}
 			},
 			AddChild: func(parent, o fyne.CanvasObject) {
-				over := o.(*container.ThemeOverride)
+				over := parent.(*container.ThemeOverride)
 				over.Content = o
 				over.Refresh()
 			},
+			ReplaceChild: replaceContent,
 			Create: func(c Context) fyne.CanvasObject {
-				return container.NewThemeOverride(container.NewStack(), c.Theme())
+				return container.NewThemeOverride(container.NewStack(), ThemeOf(c))
 			},
 			Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
 				props := c.Metadata()[obj]
@@ -493,7 +511,7 @@ func initContainers() {
 				custom := widget.NewMultiLineEntry()
 				custom.SetText(props["data"])
 				custom.OnChanged = func(s string) {
-					th, err := theme.FromJSONWithFallback(s, c.Theme())
+					th, err := theme.FromJSONWithFallback(s, ThemeOf(c))
 					if err != nil {
 						return
 					}
@@ -526,6 +544,9 @@ func initContainers() {
 		},
 	}
 
+	Containers["*widget.Accordion"] = initAccordionWidget()
+	Containers["*widget.Card"] = initCardWidget()
+	Containers["*widget.Form"] = initFormWidget()
 	Containers["*widget.Scroll"] = Containers["*container.Scroll"] // internal widget name may be used
 
 	ContainerNames = extractNames(Containers)
@@ -10,7 +10,11 @@ import (
 
 // GoString generates Go code for the given type and object
 func GoString(clazz string, obj fyne.CanvasObject, c Context, defs map[string]string) string {
-	info := Lookup(clazz)
+	if IsComponent(obj, c) {
+		return componentCode(obj, c)
+	}
+
+	info := RegistryOf(c).Lookup(clazz)
 	if info == nil {
 		return ""
 	}
@@ -20,13 +24,18 @@ func GoString(clazz string, obj fyne.CanvasObject, c Context, defs map[string]st
 	}
 
 	buf := bytes.Buffer{}
-	fallbackPrint(reflect.ValueOf(obj), &buf)
+	fallbackPrintFields(reflect.ValueOf(obj), &buf, themeFieldCode(obj, c))
 	return widgetRef(obj, c, defs, buf.String())
 }
 
 // fallbackPrint is derived from printValue in the BSD licensed Go source code at: src/fmt/print.go.
 // We use it here as a fallback Go printer that handles only exported fields.
 func fallbackPrint(value reflect.Value, buf *bytes.Buffer) {
+	fallbackPrintFields(value, buf, nil)
+}
+
+// fallbackPrintFields prints a value like fallbackPrint, using the provided code for the named fields of the outer struct.
+func fallbackPrintFields(value reflect.Value, buf *bytes.Buffer, fields map[string]string) {
 	switch value.Kind() {
 	case reflect.Struct:
 		t := value.Type()
@@ -53,6 +62,10 @@ func fallbackPrint(value reflect.Value, buf *bytes.Buffer) {
 
 			buf.WriteString(visible[i].Name)
 			buf.WriteByte(':')
+			if code, ok := fields[visible[i].Name]; ok {
+				buf.WriteString(code)
+				continue
+			}
 			fallbackPrint(f2, buf)
 		}
 		buf.WriteByte('}')
@@ -67,7 +80,7 @@ func fallbackPrint(value reflect.Value, buf *bytes.Buffer) {
 		switch a := value.Elem(); a.Kind() {
 		case reflect.Array, reflect.Slice, reflect.Struct, reflect.Map:
 			buf.WriteByte('&')
-			fallbackPrint(a, buf)
+			fallbackPrintFields(a, buf, fields)
 			return
 		}
 		fallthrough
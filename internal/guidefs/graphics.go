@@ -36,7 +36,7 @@ func initGraphics() {
 				rect.StrokeColor = color.Black
 				return rect
 			},
-			Edit: func(obj fyne.CanvasObject, _ Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
+			Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
 				a := obj.(*canvas.Arc)
 				return []*widget.FormItem{
 					widget.NewFormItem("Start Angle", newIntSliderButton(float64(a.StartAngle), -360, 360, func(f float64) {
@@ -49,12 +49,12 @@ func initGraphics() {
 						a.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("Fill", newColorButton(a.FillColor, func(c color.Color) {
+					widget.NewFormItem("Fill", newThemeColorButton(a, "FillColor", c, a.FillColor, func(c color.Color) {
 						a.FillColor = c
 						a.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("Corner", newIntSliderButton(float64(a.CornerRadius), 0, 32, func(f float64) {
+					widget.NewFormItem("Corner", newThemeSizeButton(a, "CornerRadius", c, float64(a.CornerRadius), 0, 32, func(f float64) {
 						a.CornerRadius = float32(f)
 						a.Refresh()
 						onchanged()
@@ -65,21 +65,19 @@ func initGraphics() {
 						onchanged()
 					})),
 
-					widget.NewFormItem("Stroke", newIntSliderButton(float64(a.StrokeWidth), 0, 32, func(f float64) {
+					widget.NewFormItem("Stroke", newThemeSizeButton(a, "StrokeWidth", c, float64(a.StrokeWidth), 0, 32, func(f float64) {
 						a.StrokeWidth = float32(f)
 						a.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("Color", newColorButton(a.StrokeColor, func(c color.Color) {
+					widget.NewFormItem("Color", newThemeColorButton(a, "StrokeColor", c, a.StrokeColor, func(c color.Color) {
 						a.StrokeColor = c
 						a.Refresh()
 						onchanged()
 					})),
 				}
 			},
-			Packages: func(_ fyne.CanvasObject, _ Context) []string {
-				return []string{"canvas", "image/color"}
-			},
+			Packages: graphicPackages,
 		},
 		"*canvas.Circle": {
 			Name: "Circle",
@@ -88,29 +86,27 @@ func initGraphics() {
 				rect.StrokeColor = color.Black
 				return rect
 			},
-			Edit: func(obj fyne.CanvasObject, _ Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
+			Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
 				r := obj.(*canvas.Circle)
 				return []*widget.FormItem{
-					widget.NewFormItem("Fill", newColorButton(r.FillColor, func(c color.Color) {
+					widget.NewFormItem("Fill", newThemeColorButton(r, "FillColor", c, r.FillColor, func(c color.Color) {
 						r.FillColor = c
 						r.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("Stroke", newIntSliderButton(float64(r.StrokeWidth), 0, 32, func(f float64) {
+					widget.NewFormItem("Stroke", newThemeSizeButton(r, "StrokeWidth", c, float64(r.StrokeWidth), 0, 32, func(f float64) {
 						r.StrokeWidth = float32(f)
 						r.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("Color", newColorButton(r.StrokeColor, func(c color.Color) {
+					widget.NewFormItem("Color", newThemeColorButton(r, "StrokeColor", c, r.StrokeColor, func(c color.Color) {
 						r.StrokeColor = c
 						r.Refresh()
 						onchanged()
 					})),
 				}
 			},
-			Packages: func(_ fyne.CanvasObject, _ Context) []string {
-				return []string{"canvas", "image/color"}
-			},
+			Packages: graphicPackages,
 		},
 		"*canvas.Image": initImageGraphic(),
 		"*canvas.LinearGradient": {
@@ -118,7 +114,7 @@ func initGraphics() {
 			Create: func(Context) fyne.CanvasObject {
 				return &canvas.LinearGradient{StartColor: color.White}
 			},
-			Edit: func(obj fyne.CanvasObject, _ Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
+			Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
 				r := obj.(*canvas.LinearGradient)
 				angleSlide := widget.NewSlider(0, 360)
 				angleSlide.Step = 90
@@ -128,12 +124,12 @@ func initGraphics() {
 					onchanged()
 				}
 				return []*widget.FormItem{
-					widget.NewFormItem("Start", newColorButton(r.StartColor, func(c color.Color) {
+					widget.NewFormItem("Start", newThemeColorButton(r, "StartColor", c, r.StartColor, func(c color.Color) {
 						r.StartColor = c
 						r.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("End", newColorButton(r.EndColor, func(c color.Color) {
+					widget.NewFormItem("End", newThemeColorButton(r, "EndColor", c, r.EndColor, func(c color.Color) {
 						r.EndColor = c
 						r.Refresh()
 						onchanged()
@@ -141,9 +137,7 @@ func initGraphics() {
 					widget.NewFormItem("Angle", angleSlide),
 				}
 			},
-			Packages: func(_ fyne.CanvasObject, _ Context) []string {
-				return []string{"canvas", "image/color"}
-			},
+			Packages: graphicPackages,
 		},
 		"*canvas.Polygon": {
 			Name: "Polygon",
@@ -152,7 +146,7 @@ func initGraphics() {
 				rect.StrokeColor = color.Black
 				return rect
 			},
-			Edit: func(obj fyne.CanvasObject, _ Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
+			Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
 				p := obj.(*canvas.Polygon)
 				return []*widget.FormItem{
 					widget.NewFormItem("Sides", newIntSliderButton(float64(p.Sides), 3, 18, func(f float64) {
@@ -160,12 +154,12 @@ func initGraphics() {
 						p.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("Fill", newColorButton(p.FillColor, func(c color.Color) {
+					widget.NewFormItem("Fill", newThemeColorButton(p, "FillColor", c, p.FillColor, func(c color.Color) {
 						p.FillColor = c
 						p.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("Corner", newIntSliderButton(float64(p.CornerRadius), 0, 32, func(f float64) {
+					widget.NewFormItem("Corner", newThemeSizeButton(p, "CornerRadius", c, float64(p.CornerRadius), 0, 32, func(f float64) {
 						p.CornerRadius = float32(f)
 						p.Refresh()
 						onchanged()
@@ -176,45 +170,41 @@ func initGraphics() {
 						onchanged()
 					})),
 
-					widget.NewFormItem("Stroke", newIntSliderButton(float64(p.StrokeWidth), 0, 32, func(f float64) {
+					widget.NewFormItem("Stroke", newThemeSizeButton(p, "StrokeWidth", c, float64(p.StrokeWidth), 0, 32, func(f float64) {
 						p.StrokeWidth = float32(f)
 						p.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("Color", newColorButton(p.StrokeColor, func(c color.Color) {
+					widget.NewFormItem("Color", newThemeColorButton(p, "StrokeColor", c, p.StrokeColor, func(c color.Color) {
 						p.StrokeColor = c
 						p.Refresh()
 						onchanged()
 					})),
 				}
 			},
-			Packages: func(_ fyne.CanvasObject, _ Context) []string {
-				return []string{"canvas", "image/color"}
-			},
+			Packages: graphicPackages,
 		},
 		"*canvas.RadialGradient": {
 			Name: "RadialGradient",
 			Create: func(Context) fyne.CanvasObject {
 				return &canvas.RadialGradient{StartColor: color.White}
 			},
-			Edit: func(obj fyne.CanvasObject, _ Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
+			Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
 				r := obj.(*canvas.RadialGradient)
 				return []*widget.FormItem{
-					widget.NewFormItem("Start", newColorButton(r.StartColor, func(c color.Color) {
+					widget.NewFormItem("Start", newThemeColorButton(r, "StartColor", c, r.StartColor, func(c color.Color) {
 						r.StartColor = c
 						r.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("End", newColorButton(r.EndColor, func(c color.Color) {
+					widget.NewFormItem("End", newThemeColorButton(r, "EndColor", c, r.EndColor, func(c color.Color) {
 						r.EndColor = c
 						r.Refresh()
 						onchanged()
 					})),
 				}
 			},
-			Packages: func(_ fyne.CanvasObject, _ Context) []string {
-				return []string{"canvas", "image/color"}
-			},
+			Packages: graphicPackages,
 		},
 		"*canvas.Rectangle": {
 			Name: "Rectangle",
@@ -279,22 +269,22 @@ func initGraphics() {
 				aspect := widget.NewEntryWithData(binding.FloatToString(aspectData))
 
 				return []*widget.FormItem{
-					widget.NewFormItem("Fill", newColorButton(r.FillColor, func(c color.Color) {
+					widget.NewFormItem("Fill", newThemeColorButton(r, "FillColor", c, r.FillColor, func(c color.Color) {
 						r.FillColor = c
 						r.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("Corner", newIntSliderButton(float64(r.CornerRadius), 0, 32, func(f float64) {
+					widget.NewFormItem("Corner", newThemeSizeButton(r, "CornerRadius", c, float64(r.CornerRadius), 0, 32, func(f float64) {
 						r.CornerRadius = float32(f)
 						r.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("Stroke", newIntSliderButton(float64(r.StrokeWidth), 0, 32, func(f float64) {
+					widget.NewFormItem("Stroke", newThemeSizeButton(r, "StrokeWidth", c, float64(r.StrokeWidth), 0, 32, func(f float64) {
 						r.StrokeWidth = float32(f)
 						r.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("Color", newColorButton(r.StrokeColor, func(c color.Color) {
+					widget.NewFormItem("Color", newThemeColorButton(r, "StrokeColor", c, r.StrokeColor, func(c color.Color) {
 						r.StrokeColor = c
 						r.Refresh()
 						onchanged()
@@ -311,7 +301,7 @@ func initGraphics() {
 				hasMin := (minWidth != "" && minWidth != "0") || (minHeight != "" && minHeight != "0")
 
 				buf := bytes.Buffer{}
-				fallbackPrint(reflect.ValueOf(obj), &buf)
+				fallbackPrintFields(reflect.ValueOf(obj), &buf, themeFieldCode(obj, c))
 				code := buf.String()
 
 				if hasMin {
@@ -320,9 +310,7 @@ func initGraphics() {
 				}
 				return widgetRef(obj, c, defs, code)
 			},
-			Packages: func(_ fyne.CanvasObject, _ Context) []string {
-				return []string{"canvas", "image/color"}
-			},
+			Packages: graphicPackages,
 		},
 		"*canvas.Text": {
 			Name: "Text",
@@ -330,7 +318,7 @@ func initGraphics() {
 				rect := canvas.NewText("Text", color.Black)
 				return rect
 			},
-			Edit: func(obj fyne.CanvasObject, _ Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
+			Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
 				t := obj.(*canvas.Text)
 				e := widget.NewEntry()
 				e.SetText(t.Text)
@@ -361,12 +349,12 @@ func initGraphics() {
 
 				return []*widget.FormItem{
 					widget.NewFormItem("Text", e),
-					widget.NewFormItem("Color", newColorButton(t.Color, func(c color.Color) {
+					widget.NewFormItem("Color", newThemeColorButton(t, "Color", c, t.Color, func(c color.Color) {
 						t.Color = c
 						t.Refresh()
 						onchanged()
 					})),
-					widget.NewFormItem("TextSize", newIntSliderButton(float64(t.TextSize), 4, 64, func(f float64) {
+					widget.NewFormItem("TextSize", newThemeSizeButton(t, "TextSize", c, float64(t.TextSize), 4, 64, func(f float64) {
 						t.TextSize = float32(f)
 						t.Refresh()
 						onchanged()
@@ -376,9 +364,7 @@ func initGraphics() {
 					widget.NewFormItem("Monospace", mono),
 				}
 			},
-			Packages: func(_ fyne.CanvasObject, _ Context) []string {
-				return []string{"canvas", "image/color"}
-			},
+			Packages: graphicPackages,
 		},
 	}
 
@@ -486,7 +472,7 @@ func initImageGraphic() WidgetInfo {
 				}, fyne.CurrentApp().Driver().AllWindows()[0])
 			}
 
-			resSelect := newIconSelectorButton(i.Resource, func(res fyne.Resource) {
+			resSelect := newIconSelectorButton(i.Resource, c, func(res fyne.Resource) {
 				i.Resource = res
 				i.Refresh()
 				onchanged()
@@ -502,12 +488,9 @@ func initImageGraphic() WidgetInfo {
 				widget.NewFormItem("Min Height", minHeightInput),
 			}
 		},
-		Packages: func(obj fyne.CanvasObject, _ Context) []string {
+		Packages: func(obj fyne.CanvasObject, c Context) []string {
 			i := obj.(*canvas.Image)
-			if i.Resource != nil {
-				return []string{"canvas", "theme"}
-			}
-			return []string{"canvas"}
+			return append([]string{"canvas"}, resourcePackages(i.Resource, c)...)
 		},
 		Gostring: func(obj fyne.CanvasObject, c Context, defs map[string]string) string {
 			i := obj.(*canvas.Image)
@@ -518,7 +501,7 @@ func initImageGraphic() WidgetInfo {
 
 			code := ""
 			if i.Resource != nil {
-				res := "theme." + IconName(i.Resource) + "()"
+				res := ResourceCode(i.Resource, c)
 
 				if !hasMin && i.FillMode == canvas.ImageFillStretch && i.CornerRadius == 0 {
 					code = fmt.Sprintf("canvas.NewImageFromResource(%s)", res)
@@ -0,0 +1,390 @@
+package guidefs
+
+import (
+	"reflect"
+
+	"fyne.io/fyne/v2"
+)
+
+// DefaultHistoryLimit is the number of groups of changes that a History keeps for undo, unless it is changed with SetLimit.
+const DefaultHistoryLimit = 100
+
+// HistoryContext is a context that records the changes made by editors, so that they can be undone.
+type HistoryContext interface {
+	// History returns the record of changes made by editors, it may be nil.
+	History() *History
+}
+
+// HistoryOf returns the history of a context, or nil if it does not implement [HistoryContext].
+func HistoryOf(c Context) *History {
+	if hc, ok := c.(HistoryContext); ok {
+		return hc.History()
+	}
+	return nil
+}
+
+// History records the changes made to objects so that they can be undone and redone.
+type History struct {
+	undo, redo []*Transaction
+	open       *Transaction
+	depth      int
+	limit      int
+}
+
+// Transaction is a group of changes that are undone or redone together.
+type Transaction struct {
+	Label   string
+	Changes []*Change
+}
+
+// Change is a reversible edit to one object, with the old and new values of the fields and metadata it changed.
+// Items holds the changes to values that the object refers to by pointer, such as the tabs of an AppTabs.
+type Change struct {
+	Object   fyne.CanvasObject
+	Fields   []FieldChange
+	Metadata []MetadataChange
+	Items    []ItemChange
+}
+
+// ItemChange is the old and new values of the exported fields of an item held in a slice of an object,
+// for example a `*container.TabItem` or `*widget.ToolbarAction`. Item is the pointer to the item.
+type ItemChange struct {
+	Item   interface{}
+	Fields []FieldChange
+}
+
+// FieldChange is the old and new value of an exported field of an object.
+type FieldChange struct {
+	Name     string
+	Old, New interface{}
+}
+
+// MetadataChange is the old and new value of a metadata key, an empty value means the key was not set.
+type MetadataChange struct {
+	Key      string
+	Old, New string
+}
+
+// Begin starts a group of changes, so that everything recorded until the matching call to End is undone in one step.
+// Groups may be nested, the outer group contains all of the changes.
+func (h *History) Begin(label string) {
+	if h.depth == 0 {
+		h.open = &Transaction{Label: label}
+	}
+	h.depth++
+}
+
+// End finishes a group of changes that was started with Begin.
+func (h *History) End() {
+	if h.depth == 0 {
+		return
+	}
+	h.depth--
+	if h.depth > 0 {
+		return
+	}
+
+	t := h.open
+	h.open = nil
+	if len(t.Changes) > 0 {
+		h.push(t)
+	}
+}
+
+// Record adds a change to the history, or to the open group if there is one.
+func (h *History) Record(c *Change) {
+	if c == nil {
+		return
+	}
+
+	if h.open != nil {
+		h.open.Changes = append(h.open.Changes, c)
+		return
+	}
+	h.push(&Transaction{Changes: []*Change{c}})
+}
+
+// CanUndo returns true if there is a change that can be undone.
+func (h *History) CanUndo() bool {
+	return len(h.undo) > 0
+}
+
+// CanRedo returns true if there is a change that was undone and can be applied again.
+func (h *History) CanRedo() bool {
+	return len(h.redo) > 0
+}
+
+// Undo reverts the most recent group of changes, returning false if there was nothing to undo.
+func (h *History) Undo(c Context) bool {
+	if len(h.undo) == 0 {
+		return false
+	}
+
+	t := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	for i := len(t.Changes) - 1; i >= 0; i-- {
+		t.Changes[i].apply(c, true)
+	}
+	h.redo = append(h.redo, t)
+	return true
+}
+
+// Redo applies the most recently undone group of changes again, returning false if there was nothing to redo.
+func (h *History) Redo(c Context) bool {
+	if len(h.redo) == 0 {
+		return false
+	}
+
+	t := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	for _, change := range t.Changes {
+		change.apply(c, false)
+	}
+	h.undo = append(h.undo, t)
+	return true
+}
+
+// SetLimit sets the number of groups of changes that are kept for undo, discarding the oldest if there are more.
+// A limit of 0 or less uses DefaultHistoryLimit.
+func (h *History) SetLimit(limit int) {
+	h.limit = limit
+	h.trim()
+}
+
+// Clear forgets all of the changes that could be undone or redone, and any group that is open.
+// It should be called when the objects are replaced, such as when a new document is loaded.
+func (h *History) Clear() {
+	h.undo, h.redo = nil, nil
+	h.open, h.depth = nil, 0
+}
+
+func (h *History) push(t *Transaction) {
+	h.undo = append(h.undo, t)
+	h.redo = nil
+	h.trim()
+}
+
+// trim discards the oldest changes beyond the limit, so that the objects they refer to can be released.
+func (h *History) trim() {
+	limit := h.limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	if extra := len(h.undo) - limit; extra > 0 {
+		h.undo = append([]*Transaction(nil), h.undo[extra:]...)
+	}
+}
+
+func (ch *Change) apply(c Context, old bool) {
+	applyFields(reflect.ValueOf(ch.Object).Elem(), ch.Fields, old)
+	for _, item := range ch.Items {
+		applyFields(reflect.ValueOf(item.Item).Elem(), item.Fields, old)
+	}
+
+	if len(ch.Metadata) > 0 {
+		props := c.Metadata()[ch.Object]
+		if props == nil {
+			props = make(map[string]string)
+			c.Metadata()[ch.Object] = props
+		}
+		for _, m := range ch.Metadata {
+			val := m.New
+			if old {
+				val = m.Old
+			}
+			if val == "" {
+				delete(props, m.Key)
+			} else {
+				props[m.Key] = val
+			}
+		}
+		ApplyColumnWidths(ch.Object, c)
+	}
+	ch.Object.Refresh()
+}
+
+func applyFields(v reflect.Value, fields []FieldChange, old bool) {
+	for _, f := range fields {
+		val := f.New
+		if old {
+			val = f.Old
+		}
+		field := v.FieldByName(f.Name)
+		if val == nil {
+			field.Set(reflect.Zero(field.Type()))
+		} else {
+			field.Set(copyValue(reflect.ValueOf(val)))
+		}
+	}
+}
+
+// Snapshot holds the exported fields and metadata of an object, so that changes to it can be found later.
+// The fields of items that the object holds by pointer in a slice, such as tabs or toolbar actions, are also kept.
+type Snapshot struct {
+	obj    fyne.CanvasObject
+	fields map[string]reflect.Value
+	props  map[string]string
+	items  []itemSnapshot
+}
+
+type itemSnapshot struct {
+	item   reflect.Value
+	fields map[string]reflect.Value
+}
+
+// TakeSnapshot copies the current state of an object, it returns nil if the object is not a pointer to a struct.
+func TakeSnapshot(obj fyne.CanvasObject, c Context) *Snapshot {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	s := &Snapshot{obj: obj, fields: snapshotFields(v.Elem()), props: make(map[string]string)}
+	for _, field := range s.fields {
+		if field.Kind() != reflect.Slice {
+			continue
+		}
+		for i := 0; i < field.Len(); i++ {
+			item := field.Index(i)
+			if item.Kind() == reflect.Interface {
+				item = item.Elem()
+			}
+			if item.Kind() == reflect.Ptr && !item.IsNil() && item.Elem().Kind() == reflect.Struct {
+				s.items = append(s.items, itemSnapshot{item: item, fields: snapshotFields(item.Elem())})
+			}
+		}
+	}
+	for k, val := range c.Metadata()[obj] {
+		s.props[k] = val
+	}
+	return s
+}
+
+// Diff returns the changes made to the object since the snapshot was taken, or nil if nothing changed.
+func (s *Snapshot) Diff(c Context) *Change {
+	if s == nil {
+		return nil
+	}
+
+	ch := &Change{Object: s.obj, Fields: diffFields(reflect.ValueOf(s.obj).Elem(), s.fields)}
+	for _, item := range s.items {
+		if fields := diffFields(item.item.Elem(), item.fields); len(fields) > 0 {
+			ch.Items = append(ch.Items, ItemChange{Item: item.item.Interface(), Fields: fields})
+		}
+	}
+
+	props := c.Metadata()[s.obj]
+	for k, val := range props {
+		if s.props[k] != val {
+			ch.Metadata = append(ch.Metadata, MetadataChange{Key: k, Old: s.props[k], New: val})
+		}
+	}
+	for k, val := range s.props {
+		if _, ok := props[k]; !ok && val != "" {
+			ch.Metadata = append(ch.Metadata, MetadataChange{Key: k, Old: val})
+		}
+	}
+
+	if len(ch.Fields) == 0 && len(ch.Metadata) == 0 && len(ch.Items) == 0 {
+		return nil
+	}
+	return ch
+}
+
+// snapshotFields copies the exported fields of a struct, including those promoted from embedded structs.
+// Functions are left out as they cannot be compared.
+func snapshotFields(v reflect.Value) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		if !f.IsExported() || f.Anonymous || f.Type.Kind() == reflect.Func {
+			continue
+		}
+		field, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			continue // promoted through a nil pointer
+		}
+		fields[f.Name] = copyValue(field)
+	}
+	return fields
+}
+
+// diffFields returns the changes to the fields of a struct since they were copied by snapshotFields.
+func diffFields(v reflect.Value, old map[string]reflect.Value) []FieldChange {
+	var changes []FieldChange
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		prev, ok := old[f.Name]
+		if !ok {
+			continue
+		}
+		field, err := v.FieldByIndexErr(f.Index)
+		if err != nil || shallowEqual(prev, field) {
+			continue
+		}
+
+		changes = append(changes, FieldChange{Name: f.Name, Old: prev.Interface(), New: copyValue(field).Interface()})
+	}
+	return changes
+}
+
+// copyValue returns a copy of a value that does not share the backing array of a slice.
+func copyValue(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	if v.Kind() == reflect.Slice && !v.IsNil() {
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(s, v)
+		out.Set(s)
+		return out
+	}
+
+	out.Set(v)
+	return out
+}
+
+// shallowEqual compares two values without following pointers, so that large object trees are not walked.
+// Functions are always considered equal as they cannot be compared.
+func shallowEqual(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Func:
+		return true
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer()
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		if a.Elem().Type() != b.Elem().Type() {
+			return false
+		}
+		return shallowEqual(a.Elem(), b.Elem())
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() || (a.Kind() == reflect.Slice && a.IsNil() != b.IsNil()) {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !shallowEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !shallowEqual(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	}
+	return true
+}
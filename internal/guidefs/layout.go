@@ -14,18 +14,28 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
-type layoutInfo struct {
+// LayoutInfo contains the functions that create and edit the layout of a container, and generate its code
+type LayoutInfo struct {
+	// Create returns the layout for a container, configured from the container metadata
 	Create func(*fyne.Container, Context) fyne.Layout
-	Edit   func(*fyne.Container, Context) []*widget.FormItem
-	goText func(*fyne.Container, Context, map[string]string) string
+	// Edit returns the form items that change the layout settings of a container, it may be nil
+	Edit func(*fyne.Container, Context) []*widget.FormItem
+	// Gostring returns the Go code that creates a container with this layout and its children.
+	// It is only nil for the built in layouts, where the code calls `container.New<name>` with the children.
+	Gostring func(*fyne.Container, Context, map[string]string) string
+
+	// Properties lists the metadata keys used by this layout, with a description of their value
+	Properties map[string]string
+	// Packages returns the packages that the generated code needs for this layout, it may be nil
+	Packages func(*fyne.Container, Context) []string
 }
 
 var (
-	// layoutNames is an array with the list of names of all the Layouts
-	layoutNames = extractLayoutNames()
+	// LayoutNames is an array with the list of names of all the Layouts
+	LayoutNames = extractLayoutNames()
 
 	// Layouts maps container names to layout information to create and edit containers, and generate code
-	Layouts = map[string]layoutInfo{
+	Layouts = map[string]LayoutInfo{
 		"Border": {
 			func(c *fyne.Container, d Context) fyne.Layout {
 				props := d.Metadata()[c]
@@ -192,6 +202,13 @@ var (
 				str.WriteString(")")
 				return str.String()
 			},
+			map[string]string{
+				"top":    "index of the object in Objects to place at the top",
+				"bottom": "index of the object in Objects to place at the bottom",
+				"left":   "index of the object in Objects to place on the left",
+				"right":  "index of the object in Objects to place on the right",
+			},
+			nil,
 		},
 		"Center": {
 			func(*fyne.Container, Context) fyne.Layout {
@@ -199,6 +216,8 @@ var (
 			},
 			nil,
 			nil,
+			nil,
+			nil,
 		},
 		"Form": {
 			func(*fyne.Container, Context) fyne.Layout {
@@ -206,6 +225,10 @@ var (
 			},
 			nil,
 			nil,
+			nil,
+			func(*fyne.Container, Context) []string {
+				return []string{"layout"}
+			},
 		},
 		"Grid": {
 			func(c *fyne.Container, d Context) fyne.Layout {
@@ -295,6 +318,11 @@ var (
 				str.WriteString(")")
 				return str.String()
 			},
+			map[string]string{
+				"grid_type": "either \"Columns\" or \"Rows\"",
+				"count":     "the number of columns or rows",
+			},
+			nil,
 		},
 		"GridWrap": {
 			func(c *fyne.Container, d Context) fyne.Layout {
@@ -362,6 +390,13 @@ var (
 				}
 			},
 			nil,
+			map[string]string{
+				"width":  "the width of each item",
+				"height": "the height of each item",
+			},
+			func(*fyne.Container, Context) []string {
+				return []string{"layout"}
+			},
 		},
 		"HBox": {
 			func(c *fyne.Container, d Context) fyne.Layout {
@@ -370,6 +405,8 @@ var (
 			},
 			nil,
 			nil,
+			nil,
+			nil,
 		},
 		"Max": {
 			func(_ *fyne.Container, _ Context) fyne.Layout {
@@ -377,6 +414,8 @@ var (
 			},
 			nil,
 			nil,
+			nil,
+			nil,
 		},
 		"Padded": {
 			func(_ *fyne.Container, _ Context) fyne.Layout {
@@ -384,6 +423,8 @@ var (
 			},
 			nil,
 			nil,
+			nil,
+			nil,
 		},
 		"CustomPadded": {
 			func(c *fyne.Container, d Context) fyne.Layout {
@@ -548,6 +589,15 @@ var (
 				str.WriteString(")")
 				return str.String()
 			},
+			map[string]string{
+				"top":    "padding above the content",
+				"bottom": "padding below the content",
+				"left":   "padding to the left of the content",
+				"right":  "padding to the right of the content",
+			},
+			func(*fyne.Container, Context) []string {
+				return []string{"layout"}
+			},
 		},
 		"RowWrap": {
 			func(_ *fyne.Container, _ Context) fyne.Layout {
@@ -555,6 +605,8 @@ var (
 			},
 			nil,
 			nil,
+			nil,
+			nil,
 		},
 		"Stack": {
 			func(c *fyne.Container, d Context) fyne.Layout {
@@ -562,6 +614,8 @@ var (
 			},
 			nil,
 			nil,
+			nil,
+			nil,
 		},
 		"VBox": {
 			func(c *fyne.Container, d Context) fyne.Layout {
@@ -570,6 +624,8 @@ var (
 			},
 			nil,
 			nil,
+			nil,
+			nil,
 		},
 		"WithoutLayout": {
 			func(c *fyne.Container, d Context) fyne.Layout {
@@ -617,6 +673,8 @@ var (
 				str.WriteString(")")
 				return str.String()
 			},
+			nil,
+			nil,
 		},
 	}
 )
@@ -662,9 +720,10 @@ func widgetName(o fyne.CanvasObject) string {
 func writeGoString(str *strings.Builder, c Context,
 	defs map[string]string, o fyne.CanvasObject,
 ) error {
-	clazz := TypeName(o)
+	reg := RegistryOf(c)
+	clazz := reg.TypeName(o)
 
-	if match := Lookup(clazz); match != nil {
+	if match := reg.Lookup(clazz); match != nil {
 		code := GoString(clazz, o, c, defs)
 		str.WriteString(fmt.Sprintf("\n\t\t%s", code))
 	} else {
@@ -674,6 +733,13 @@ func writeGoString(str *strings.Builder, c Context,
 	return nil
 }
 
+// ChildrenGoString returns the Go code for a list of child objects, separated by commas.
+func ChildrenGoString(c Context, defs map[string]string, objs ...fyne.CanvasObject) string {
+	str := &strings.Builder{}
+	writeGoStringExcluding(str, nil, c, defs, objs...)
+	return str.String()
+}
+
 func writeGoStringOrNil(str *strings.Builder, c Context,
 	defs map[string]string, o fyne.CanvasObject,
 ) {
@@ -6,28 +6,21 @@ import (
 	"fyne.io/fyne/v2"
 )
 
-// Lookup returns the [WidgetInfo] for the given widget type.
+// Lookup returns the [WidgetInfo] for the given widget type in the default registry.
 func Lookup(clazz string) *WidgetInfo {
-	if match, ok := Widgets[clazz]; ok {
-		return &match
-	}
-	if match, ok := Collections[clazz]; ok {
-		return &match
-	}
-	if match, ok := Containers[clazz]; ok {
-		return &match
-	}
-	if match, ok := Graphics[clazz]; ok {
-		return &match
-	}
-
-	return nil
+	return defaultRegistry.Lookup(clazz)
 }
 
 // TypeName returns the unique name for this object, i.e. "canvas.Line" or "xWidget.Map"
 func TypeName(o fyne.CanvasObject) string {
+	return defaultRegistry.TypeName(o)
+}
+
+// TypeName returns the unique name for this object using the types in this registry,
+// i.e. "canvas.Line" or "xWidget.Map"
+func (r *Registry) TypeName(o fyne.CanvasObject) string {
 	class := reflect.TypeOf(o).String()
-	info := Lookup(class)
+	info := r.Lookup(class)
 	if info == nil {
 		class = "*xW" + class[2:] // xWidget imports
 	}
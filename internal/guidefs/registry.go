@@ -0,0 +1,273 @@
+package guidefs
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"fyne.io/fyne/v2"
+)
+
+// Registry holds the widget, collection, container and graphic types, the layouts and the icons, that a builder can use.
+// It is safe to change a registry while other goroutines are looking up types in it.
+type Registry struct {
+	lock sync.RWMutex
+
+	widgets, collections, containers, graphics                  *map[string]WidgetInfo
+	widgetNames, collectionNames, containerNames, graphicsNames *[]string
+	icons                                                       *map[string]fyne.Resource
+	iconNames                                                   *[]string
+	layouts                                                     *map[string]LayoutInfo
+	layoutNames                                                 *[]string
+}
+
+// defaultRegistry refers to the package variables, so that they remain the types known to every context by default.
+var defaultRegistry = &Registry{
+	widgets: &Widgets, collections: &Collections, containers: &Containers, graphics: &Graphics,
+	widgetNames: &WidgetNames, collectionNames: &CollectionNames, containerNames: &ContainerNames,
+	graphicsNames: &GraphicsNames, icons: &Icons, iconNames: &IconNames,
+}
+
+func init() {
+	// the layout functions refer to the default registry, so the layouts are added once both are initialised
+	defaultRegistry.layouts, defaultRegistry.layoutNames = &Layouts, &LayoutNames
+}
+
+// DefaultRegistry returns the registry used by contexts that do not provide their own,
+// it contains the built in types and any that were registered globally.
+func DefaultRegistry() *Registry {
+	InitOnce()
+	return defaultRegistry
+}
+
+// RegistryOf returns the registry that a context uses to resolve types, or the default registry
+// if it does not implement [RegistryContext].
+func RegistryOf(c Context) *Registry {
+	if rc, ok := c.(RegistryContext); ok {
+		if r := rc.Registry(); r != nil {
+			return r
+		}
+	}
+	return defaultRegistry
+}
+
+// Clone returns a new registry with a copy of the types and icons in this one.
+// Changes to the new registry do not affect the original, or any other contexts.
+func (r *Registry) Clone() *Registry {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	copyInfos := func(in *map[string]WidgetInfo) *map[string]WidgetInfo {
+		out := make(map[string]WidgetInfo, len(*in))
+		for k, v := range *in {
+			out[k] = v
+		}
+		return &out
+	}
+	copyNames := func(in *[]string) *[]string {
+		out := append([]string(nil), *in...)
+		return &out
+	}
+	icons := make(map[string]fyne.Resource, len(*r.icons))
+	for k, v := range *r.icons {
+		icons[k] = v
+	}
+	layouts := make(map[string]LayoutInfo, len(*r.layouts))
+	for k, v := range *r.layouts {
+		layouts[k] = v
+	}
+
+	return &Registry{
+		widgets: copyInfos(r.widgets), collections: copyInfos(r.collections),
+		containers: copyInfos(r.containers), graphics: copyInfos(r.graphics),
+		widgetNames: copyNames(r.widgetNames), collectionNames: copyNames(r.collectionNames),
+		containerNames: copyNames(r.containerNames), graphicsNames: copyNames(r.graphicsNames),
+		icons: &icons, iconNames: copyNames(r.iconNames),
+		layouts: &layouts, layoutNames: copyNames(r.layoutNames),
+	}
+}
+
+// Lookup returns the [WidgetInfo] for the given widget type, or nil if it is not registered.
+func (r *Registry) Lookup(clazz string) *WidgetInfo {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, infos := range r.infos() {
+		if match, ok := infos[clazz]; ok {
+			return &match
+		}
+	}
+	return nil
+}
+
+// Widget returns the [WidgetInfo] for a type in the widget list only, such as the collection templates.
+func (r *Registry) Widget(clazz string) (WidgetInfo, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	info, ok := (*r.widgets)[clazz]
+	return info, ok
+}
+
+// Icon returns the icon resource with the given name, or nil.
+func (r *Registry) Icon(name string) fyne.Resource {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return (*r.icons)[name]
+}
+
+// Layout returns the information for the named container layout, and false if it is not registered.
+func (r *Registry) Layout(name string) (LayoutInfo, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	info, ok := (*r.layouts)[name]
+	return info, ok
+}
+
+// LayoutNames returns the sorted names of the container layouts.
+func (r *Registry) LayoutNames() []string {
+	return r.names(r.layoutNames)
+}
+
+// RegisterLayout adds a container layout with the given name, replacing any existing entry.
+// A layout must have a Create function, and a Gostring function unless it replaces a built in layout,
+// as the code for those calls the `container.New<name>` function.
+func (r *Registry) RegisterLayout(name string, info LayoutInfo) error {
+	if name == "" || info.Create == nil {
+		return errors.New("layout needs a name and a Create function")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	old, exists := (*r.layouts)[name]
+	if info.Gostring == nil && (!exists || old.Gostring != nil) {
+		return errors.New("layout " + name + " needs a Gostring function")
+	}
+
+	(*r.layouts)[name] = info
+	if exists {
+		return nil
+	}
+	list := make([]string, len(*r.layoutNames), len(*r.layoutNames)+1)
+	copy(list, *r.layoutNames)
+	list = append(list, name)
+	sort.Strings(list)
+	*r.layoutNames = list
+	return nil
+}
+
+// WidgetNames returns the sorted names of the widget types.
+func (r *Registry) WidgetNames() []string {
+	return r.names(r.widgetNames)
+}
+
+// CollectionNames returns the sorted names of the collection types.
+func (r *Registry) CollectionNames() []string {
+	return r.names(r.collectionNames)
+}
+
+// ContainerNames returns the sorted names of the container types.
+func (r *Registry) ContainerNames() []string {
+	return r.names(r.containerNames)
+}
+
+// GraphicsNames returns the sorted names of the graphics types.
+func (r *Registry) GraphicsNames() []string {
+	return r.names(r.graphicsNames)
+}
+
+// IconNames returns the sorted names of the icons.
+func (r *Registry) IconNames() []string {
+	return r.names(r.iconNames)
+}
+
+// RegisterWidget adds a widget type, keyed by the typed name in info.Name, replacing any existing entry.
+func (r *Registry) RegisterWidget(info WidgetInfo) {
+	r.register(*r.widgets, r.widgetNames, info)
+}
+
+// RegisterCollection adds a collection type, keyed by the typed name in info.Name, replacing any existing entry.
+func (r *Registry) RegisterCollection(info WidgetInfo) {
+	r.register(*r.collections, r.collectionNames, info)
+}
+
+// RegisterContainer adds a container type, keyed by the typed name in info.Name, replacing any existing entry.
+func (r *Registry) RegisterContainer(info WidgetInfo) {
+	r.register(*r.containers, r.containerNames, info)
+}
+
+// Replace updates the information for a type that is already registered, wherever it is found.
+// It returns the previous information so that the new functions can delegate to it.
+func (r *Registry) Replace(clazz string, info WidgetInfo) (WidgetInfo, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, infos := range r.infos() {
+		if old, ok := infos[clazz]; ok {
+			infos[clazz] = info
+			return old, true
+		}
+	}
+	return WidgetInfo{}, false
+}
+
+// Unregister removes a type from the registry and its name lists, returning false if it was not found.
+func (r *Registry) Unregister(clazz string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	found := false
+	for _, infos := range r.infos() {
+		if _, ok := infos[clazz]; ok {
+			delete(infos, clazz)
+			found = true
+		}
+	}
+	for _, names := range []*[]string{r.widgetNames, r.collectionNames, r.containerNames, r.graphicsNames} {
+		for i, name := range *names {
+			if name != clazz {
+				continue
+			}
+
+			list := make([]string, 0, len(*names)-1)
+			list = append(list, (*names)[:i]...)
+			*names = append(list, (*names)[i+1:]...)
+			break
+		}
+	}
+	return found
+}
+
+func (r *Registry) infos() []map[string]WidgetInfo {
+	return []map[string]WidgetInfo{*r.widgets, *r.collections, *r.containers, *r.graphics}
+}
+
+func (r *Registry) names(names *[]string) []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return *names
+}
+
+// register adds the information for a type to the given map, replacing any existing entry with the same name.
+// The list of names is replaced with a sorted copy that contains the name once, so slices returned earlier are unchanged.
+func (r *Registry) register(infos map[string]WidgetInfo, names *[]string, info WidgetInfo) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	infos[info.Name] = info
+	for _, name := range *names {
+		if name == info.Name {
+			return
+		}
+	}
+
+	list := make(widgetNames, len(*names), len(*names)+1)
+	copy(list, *names)
+	list = append(list, info.Name)
+	sort.Sort(list)
+	*names = list
+}
@@ -1,22 +1,41 @@
 package guidefs
 
 import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
 	"fyne.io/fyne/v2"
 )
 
+// Resource is an application resource that a GUI may use by name, in addition to the theme icons.
+// Resources are registered by name using the map returned by `ResourceContext.Resources()`.
+type Resource struct {
+	fyne.Resource
+
+	// Embed is the path of the resource file, relative to the exported Go package.
+	// If it is set the exported code loads the file using a `//go:embed` directive.
+	Embed string
+	// Bundled is the name of a variable in the exported package that holds the resource,
+	// such as one written by `fyne bundle`. It is used if Embed is not set.
+	Bundled string
+}
+
 type jsonResource struct {
 	fyne.Resource `json:"-"`
+	ctx           Context
 }
 
 func (r *jsonResource) MarshalJSON() ([]byte, error) {
-	icon := "\"" + IconName(r.Resource) + "\""
-
-	return []byte(icon), nil
+	return []byte(strconv.Quote(ResourceName(r.Resource, r.ctx))), nil
 }
 
-// WrapResource wraps a fyne.Resource for integration with JSON
-func WrapResource(r fyne.Resource) fyne.Resource {
-	return &jsonResource{r}
+// WrapResource wraps a fyne.Resource for integration with JSON, which stores it by name
+func WrapResource(r fyne.Resource, c Context) fyne.Resource {
+	return &jsonResource{Resource: r, ctx: c}
 }
 
 // IconName returns the name for an icon
@@ -34,3 +53,156 @@ func IconName(res fyne.Resource) string {
 
 	return ret
 }
+
+// ResourceName returns the name of a resource, which is the name it was registered with in the context,
+// or otherwise the name of the theme icon.
+func ResourceName(res fyne.Resource, c Context) string {
+	if name, _, ok := customResource(res, c); ok {
+		return name
+	}
+	return IconName(res)
+}
+
+// ResourcesOf returns the custom resources of a context, or nil if it does not implement [ResourceContext].
+func ResourcesOf(c Context) map[string]Resource {
+	if rc, ok := c.(ResourceContext); ok {
+		return rc.Resources()
+	}
+	return nil
+}
+
+// LookupResource returns the resource registered in the context with the given name,
+// or the theme icon of that name, or nil if neither is found.
+func LookupResource(name string, c Context) fyne.Resource {
+	if r, ok := ResourcesOf(c)[name]; ok && r.Resource != nil {
+		return r.Resource
+	}
+	return RegistryOf(c).Icon(name)
+}
+
+// ResourceNames returns the names of the resources registered in the context, in order.
+func ResourceNames(c Context) []string {
+	resources := ResourcesOf(c)
+	names := make([]string, 0, len(resources))
+	for name, r := range resources {
+		if r.Resource != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResourceCode returns the Go code that refers to a resource in exported code.
+func ResourceCode(res fyne.Resource, c Context) string {
+	if name, _, ok := customResource(res, c); ok {
+		return ResourceVariables(c)[name]
+	}
+	return "theme." + IconName(res) + "()"
+}
+
+// ResourceVariables returns the names of the variables that hold the custom resources in exported code,
+// keyed by resource name.
+// Names that would otherwise be the same, such as for "my-icon" and "my_icon", have a number added
+// in the order of the resource names, so that each resource is declared once.
+func ResourceVariables(c Context) map[string]string {
+	resources := ResourcesOf(c)
+	names := ResourceNames(c)
+	vars := make(map[string]string, len(names))
+	used := make(map[string]bool)
+	for _, name := range names { // the bundled variables are declared elsewhere, so they cannot be renamed
+		if r := resources[name]; r.Embed == "" && r.Bundled != "" {
+			vars[name] = r.Bundled
+			used[r.Bundled] = true
+		}
+	}
+	for _, name := range names {
+		if _, ok := vars[name]; ok {
+			continue
+		}
+
+		base := ResourceVariable(name, resources[name])
+		v := base
+		for i := 2; used[v]; i++ {
+			v = base + strconv.Itoa(i)
+		}
+		vars[name] = v
+		used[v] = true
+	}
+	return vars
+}
+
+// ResourceVariable returns the name of the variable that would hold a custom resource in exported code,
+// before any clash with the other resources is resolved by `ResourceVariables`.
+func ResourceVariable(name string, r Resource) string {
+	if r.Embed == "" && r.Bundled != "" {
+		return r.Bundled
+	}
+
+	id := &strings.Builder{}
+	id.WriteString("resource")
+	upper := true
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		id.WriteRune(c)
+	}
+	return id.String()
+}
+
+// resourcePackages returns the packages needed to refer to a resource in exported code.
+func resourcePackages(res fyne.Resource, c Context) []string {
+	if res == nil {
+		return nil
+	}
+	if _, _, ok := customResource(res, c); ok {
+		return nil
+	}
+	return []string{"theme"}
+}
+
+// appendPackages adds packages to a list, skipping any that it already contains.
+func appendPackages(pkgs []string, add ...string) []string {
+	for _, p := range add {
+		found := false
+		for _, exists := range pkgs {
+			if p == exists {
+				found = true
+				break
+			}
+		}
+		if !found {
+			pkgs = append(pkgs, p)
+		}
+	}
+	return pkgs
+}
+
+func customResource(res fyne.Resource, c Context) (string, Resource, bool) {
+	if res == nil || c == nil {
+		return "", Resource{}, false
+	}
+
+	for _, name := range ResourceNames(c) {
+		r := ResourcesOf(c)[name]
+		if sameResource(r.Resource, res) {
+			return name, r, true
+		}
+	}
+	return "", Resource{}, false
+}
+
+// sameResource returns true if the resources are the same value, or have the same name and content,
+// such as a resource that was loaded again from the same file.
+func sameResource(a, b fyne.Resource) bool {
+	if reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() && a == b {
+		return true
+	}
+	return a.Name() == b.Name() && bytes.Equal(a.Content(), b.Content())
+}
@@ -0,0 +1,278 @@
+package guidefs
+
+import (
+	"image/color"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+var (
+	// ThemeColorFields lists the colour fields of each graphic that may refer to a theme colour name
+	ThemeColorFields = map[string][]string{
+		"*canvas.Arc":            {"FillColor", "StrokeColor"},
+		"*canvas.Circle":         {"FillColor", "StrokeColor"},
+		"*canvas.LinearGradient": {"StartColor", "EndColor"},
+		"*canvas.Polygon":        {"FillColor", "StrokeColor"},
+		"*canvas.RadialGradient": {"StartColor", "EndColor"},
+		"*canvas.Rectangle":      {"FillColor", "StrokeColor"},
+		"*canvas.Text":           {"Color"},
+	}
+
+	// ThemeSizeFields lists the size fields of each graphic that may refer to a theme size name
+	ThemeSizeFields = map[string][]string{
+		"*canvas.Arc":       {"CornerRadius", "StrokeWidth"},
+		"*canvas.Circle":    {"StrokeWidth"},
+		"*canvas.Polygon":   {"CornerRadius", "StrokeWidth"},
+		"*canvas.Rectangle": {"CornerRadius", "StrokeWidth"},
+		"*canvas.Text":      {"TextSize"},
+	}
+
+	themeColorNames = map[fyne.ThemeColorName]string{
+		theme.ColorNameBackground:          "ColorNameBackground",
+		theme.ColorNameButton:              "ColorNameButton",
+		theme.ColorNameDisabledButton:      "ColorNameDisabledButton",
+		theme.ColorNameDisabled:            "ColorNameDisabled",
+		theme.ColorNameError:               "ColorNameError",
+		theme.ColorNameFocus:               "ColorNameFocus",
+		theme.ColorNameForeground:          "ColorNameForeground",
+		theme.ColorNameForegroundOnError:   "ColorNameForegroundOnError",
+		theme.ColorNameForegroundOnPrimary: "ColorNameForegroundOnPrimary",
+		theme.ColorNameForegroundOnSuccess: "ColorNameForegroundOnSuccess",
+		theme.ColorNameForegroundOnWarning: "ColorNameForegroundOnWarning",
+		theme.ColorNameHeaderBackground:    "ColorNameHeaderBackground",
+		theme.ColorNameHover:               "ColorNameHover",
+		theme.ColorNameHyperlink:           "ColorNameHyperlink",
+		theme.ColorNameInputBackground:     "ColorNameInputBackground",
+		theme.ColorNameInputBorder:         "ColorNameInputBorder",
+		theme.ColorNameMenuBackground:      "ColorNameMenuBackground",
+		theme.ColorNameOverlayBackground:   "ColorNameOverlayBackground",
+		theme.ColorNamePlaceHolder:         "ColorNamePlaceHolder",
+		theme.ColorNamePressed:             "ColorNamePressed",
+		theme.ColorNamePrimary:             "ColorNamePrimary",
+		theme.ColorNameScrollBar:           "ColorNameScrollBar",
+		theme.ColorNameScrollBarBackground: "ColorNameScrollBarBackground",
+		theme.ColorNameSelection:           "ColorNameSelection",
+		theme.ColorNameSeparator:           "ColorNameSeparator",
+		theme.ColorNameShadow:              "ColorNameShadow",
+		theme.ColorNameSuccess:             "ColorNameSuccess",
+		theme.ColorNameWarning:             "ColorNameWarning",
+	}
+
+	themeSizeNames = map[fyne.ThemeSizeName]string{
+		theme.SizeNameCaptionText:          "SizeNameCaptionText",
+		theme.SizeNameHeadingText:          "SizeNameHeadingText",
+		theme.SizeNameInlineIcon:           "SizeNameInlineIcon",
+		theme.SizeNameInnerPadding:         "SizeNameInnerPadding",
+		theme.SizeNameInputBorder:          "SizeNameInputBorder",
+		theme.SizeNameInputRadius:          "SizeNameInputRadius",
+		theme.SizeNameLineSpacing:          "SizeNameLineSpacing",
+		theme.SizeNamePadding:              "SizeNamePadding",
+		theme.SizeNameScrollBar:            "SizeNameScrollBar",
+		theme.SizeNameScrollBarRadius:      "SizeNameScrollBarRadius",
+		theme.SizeNameScrollBarSmall:       "SizeNameScrollBarSmall",
+		theme.SizeNameSelectionRadius:      "SizeNameSelectionRadius",
+		theme.SizeNameSeparatorThickness:   "SizeNameSeparatorThickness",
+		theme.SizeNameSubHeadingText:       "SizeNameSubHeadingText",
+		theme.SizeNameText:                 "SizeNameText",
+		theme.SizeNameWindowButtonHeight:   "SizeNameWindowButtonHeight",
+		theme.SizeNameWindowButtonIcon:     "SizeNameWindowButtonIcon",
+		theme.SizeNameWindowButtonRadius:   "SizeNameWindowButtonRadius",
+		theme.SizeNameWindowTitleBarHeight: "SizeNameWindowTitleBarHeight",
+	}
+)
+
+// ThemeReferenceKey returns the metadata key that holds the theme colour or size name used by a field.
+func ThemeReferenceKey(field string) string {
+	return "theme" + field
+}
+
+// ApplyThemeReferences updates the colour and size fields of an object that refer to theme names,
+// so that they preview the values of the current context theme.
+func ApplyThemeReferences(obj fyne.CanvasObject, c Context) {
+	props := c.Metadata()[obj]
+	if len(props) == 0 {
+		return
+	}
+
+	clazz := RegistryOf(c).TypeName(obj)
+	changed := false
+	for _, field := range ThemeColorFields[clazz] {
+		if name := props[ThemeReferenceKey(field)]; name != "" {
+			changed = setField(obj, field, reflect.ValueOf(themeColor(fyne.ThemeColorName(name), c))) || changed
+		}
+	}
+	for _, field := range ThemeSizeFields[clazz] {
+		if name := props[ThemeReferenceKey(field)]; name != "" {
+			changed = setField(obj, field, reflect.ValueOf(ThemeOf(c).Size(fyne.ThemeSizeName(name)))) || changed
+		}
+	}
+
+	if changed {
+		obj.Refresh()
+	}
+}
+
+// themeFieldCode returns the Go code for each field of an object that refers to a theme name, keyed by field name.
+func themeFieldCode(obj fyne.CanvasObject, c Context) map[string]string {
+	props := c.Metadata()[obj]
+	clazz := RegistryOf(c).TypeName(obj)
+	code := make(map[string]string)
+	for _, field := range ThemeColorFields[clazz] {
+		if name := props[ThemeReferenceKey(field)]; name != "" {
+			code[field] = "theme.Color(" + themeColorNameCode(fyne.ThemeColorName(name)) + ")"
+		}
+	}
+	for _, field := range ThemeSizeFields[clazz] {
+		if name := props[ThemeReferenceKey(field)]; name != "" {
+			code[field] = "theme.Size(" + themeSizeNameCode(fyne.ThemeSizeName(name)) + ")"
+		}
+	}
+	return code
+}
+
+// graphicPackages returns the packages needed by the code for a graphic,
+// including the theme package only if it refers to theme names and image/color only if a literal colour remains.
+func graphicPackages(obj fyne.CanvasObject, c Context) []string {
+	refs := themeFieldCode(obj, c)
+	pkgs := []string{"canvas"}
+
+	v := reflect.ValueOf(obj).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() || f.Type != reflect.TypeOf((*color.Color)(nil)).Elem() {
+			continue
+		}
+		if _, ok := refs[f.Name]; !ok && !v.Field(i).IsNil() {
+			pkgs = append(pkgs, "image/color")
+			break
+		}
+	}
+
+	if len(refs) > 0 {
+		pkgs = append(pkgs, "theme")
+	}
+	return pkgs
+}
+
+func themeProps(obj fyne.CanvasObject, c Context) map[string]string {
+	props := c.Metadata()[obj]
+	if props == nil {
+		props = make(map[string]string)
+		c.Metadata()[obj] = props
+	}
+	return props
+}
+
+func setField(obj fyne.CanvasObject, field string, value reflect.Value) bool {
+	f := reflect.ValueOf(obj).Elem().FieldByName(field)
+	if !f.IsValid() || !f.CanSet() || !value.Type().AssignableTo(f.Type()) {
+		return false
+	}
+
+	f.Set(value)
+	return true
+}
+
+// ThemeOf returns the theme of a context, or the default theme if the context does not set one.
+func ThemeOf(c Context) fyne.Theme {
+	if th := c.Theme(); th != nil {
+		return th
+	}
+	return theme.DefaultTheme()
+}
+
+func themeColor(name fyne.ThemeColorName, c Context) color.Color {
+	variant := theme.VariantDark
+	if a := fyne.CurrentApp(); a != nil {
+		variant = a.Settings().ThemeVariant()
+	}
+	return ThemeOf(c).Color(name, variant)
+}
+
+func themeColorNameCode(name fyne.ThemeColorName) string {
+	if id, ok := themeColorNames[name]; ok {
+		return "theme." + id
+	}
+	return strconv.Quote(string(name))
+}
+
+func themeSizeNameCode(name fyne.ThemeSizeName) string {
+	if id, ok := themeSizeNames[name]; ok {
+		return "theme." + id
+	}
+	return strconv.Quote(string(name))
+}
+
+// newThemeColorButton returns a colour editor that can also choose a theme colour name for the field of an object.
+func newThemeColorButton(obj fyne.CanvasObject, field string, c Context, col color.Color, fn func(color.Color)) fyne.CanvasObject {
+	props := themeProps(obj, c)
+	key := ThemeReferenceKey(field)
+	names := make([]string, 0, len(themeColorNames)+1)
+	names = append(names, "")
+	for name := range themeColorNames {
+		names = append(names, string(name))
+	}
+	sort.Strings(names[1:])
+
+	choose := widget.NewSelect(names, nil)
+	choose.PlaceHolder = "(custom)"
+	choose.SetSelected(props[key])
+
+	custom := newColorButton(col, func(col color.Color) {
+		if choose.Selected != "" && themeColor(fyne.ThemeColorName(choose.Selected), c) != col {
+			choose.ClearSelected() // typing a colour replaces the theme reference
+		}
+		fn(col)
+	})
+	choose.OnChanged = func(name string) {
+		if name == "" {
+			delete(props, key)
+			return
+		}
+
+		props[key] = name
+		fn(themeColor(fyne.ThemeColorName(name), c))
+	}
+	return container.NewBorder(nil, nil, nil, choose, custom)
+}
+
+// newThemeSizeButton returns a size editor that can also choose a theme size name for the field of an object.
+func newThemeSizeButton(obj fyne.CanvasObject, field string, c Context, f float64, start, end float64,
+	fn func(float64),
+) fyne.CanvasObject {
+	props := themeProps(obj, c)
+	key := ThemeReferenceKey(field)
+	names := make([]string, 0, len(themeSizeNames)+1)
+	names = append(names, "")
+	for name := range themeSizeNames {
+		names = append(names, string(name))
+	}
+	sort.Strings(names[1:])
+
+	choose := widget.NewSelect(names, nil)
+	choose.PlaceHolder = "(custom)"
+	choose.SetSelected(props[key])
+
+	custom := newIntSliderButton(f, start, end, func(f float64) {
+		if choose.Selected != "" && float64(ThemeOf(c).Size(fyne.ThemeSizeName(choose.Selected))) != f {
+			choose.ClearSelected()
+		}
+		fn(f)
+	})
+	choose.OnChanged = func(name string) {
+		if name == "" {
+			delete(props, key)
+			return
+		}
+
+		props[key] = name
+		fn(float64(ThemeOf(c).Size(fyne.ThemeSizeName(name))))
+	}
+	return container.NewBorder(nil, nil, nil, choose, custom)
+}
@@ -0,0 +1,191 @@
+package guidefs
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// childIndexProperties lists the container metadata keys, for each layout, that hold the index of a child object.
+var childIndexProperties = map[string][]string{
+	"Border": {"top", "bottom", "left", "right"},
+}
+
+// ChildIndexKeys returns the container metadata keys that refer to the child at the given index.
+func ChildIndexKeys(c *fyne.Container, index int, ctx Context) []string {
+	props := ctx.Metadata()[c]
+	var keys []string
+	for _, key := range childIndexProperties[props["layout"]] {
+		if props[key] == strconv.Itoa(index) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// SetChildIndexKeys stores the index of a child in the given container metadata keys and updates the layout.
+func SetChildIndexKeys(c *fyne.Container, index int, keys []string, ctx Context) {
+	if len(keys) == 0 {
+		return
+	}
+
+	props := ctx.Metadata()[c]
+	for _, key := range keys {
+		props[key] = strconv.Itoa(index)
+	}
+	relayout(c, ctx)
+}
+
+func insertContainerChild(parent fyne.CanvasObject, index int, child fyne.CanvasObject, ctx Context) {
+	c := parent.(*fyne.Container)
+	c.Objects = append(c.Objects, nil)
+	copy(c.Objects[index+1:], c.Objects[index:])
+	c.Objects[index] = child
+
+	shiftChildIndexes(c, index, 1, ctx)
+	relayout(c, ctx)
+}
+
+func removeContainerChild(parent fyne.CanvasObject, index int, ctx Context) {
+	c := parent.(*fyne.Container)
+	c.Objects = append(c.Objects[:index], c.Objects[index+1:]...)
+
+	props := ctx.Metadata()[c]
+	for _, key := range childIndexProperties[props["layout"]] {
+		if props[key] == strconv.Itoa(index) {
+			delete(props, key)
+		}
+	}
+	shiftChildIndexes(c, index+1, -1, ctx)
+	relayout(c, ctx)
+}
+
+func replaceContainerChild(parent fyne.CanvasObject, index int, child fyne.CanvasObject, ctx Context) {
+	c := parent.(*fyne.Container)
+	c.Objects[index] = child
+	relayout(c, ctx)
+}
+
+// shiftChildIndexes moves the child indexes in the container metadata that are at least from by delta.
+func shiftChildIndexes(c *fyne.Container, from, delta int, ctx Context) {
+	props := ctx.Metadata()[c]
+	for _, key := range childIndexProperties[props["layout"]] {
+		if i, err := strconv.Atoi(props[key]); err == nil && props[key] != "" && i >= from {
+			props[key] = strconv.Itoa(i + delta)
+		}
+	}
+}
+
+// relayout creates the layout of a container again, as some layouts hold references to the child objects.
+func relayout(c *fyne.Container, ctx Context) {
+	if lay, ok := RegistryOf(ctx).Layout(ctx.Metadata()[c]["layout"]); ok {
+		c.Layout = lay.Create(c, ctx)
+	}
+	c.Refresh()
+}
+
+// replaceContent sets the only child of a container that holds a single content object.
+func replaceContent(parent fyne.CanvasObject, _ int, child fyne.CanvasObject, _ Context) {
+	switch p := parent.(type) {
+	case *container.Clip:
+		p.Content = child
+	case *container.Navigation:
+		p.Root = child
+	case *container.Scroll:
+		p.Content = child
+	case *container.ThemeOverride:
+		p.Content = child
+	}
+	parent.Refresh()
+}
+
+// itemIndex returns the index of the item that holds the child at the given index,
+// skipping items that have no child object.
+func itemIndex(index, count int, hasChild func(int) bool) int {
+	for i := 0; i < count; i++ {
+		if !hasChild(i) {
+			continue
+		}
+		if index == 0 {
+			return i
+		}
+		index--
+	}
+	return count + index
+}
+
+func insertTabChild(parent fyne.CanvasObject, index int, child fyne.CanvasObject, _ Context) {
+	tabs := parent.(*container.AppTabs)
+	tabs.Items = append(tabs.Items, nil)
+	copy(tabs.Items[index+1:], tabs.Items[index:])
+	tabs.Items[index] = container.NewTabItem("Untitled", child)
+	tabs.Refresh()
+}
+
+func removeTabChild(parent fyne.CanvasObject, index int, _ Context) {
+	tabs := parent.(*container.AppTabs)
+	tabs.RemoveIndex(index)
+}
+
+func replaceTabChild(parent fyne.CanvasObject, index int, child fyne.CanvasObject, _ Context) {
+	tabs := parent.(*container.AppTabs)
+	tabs.Items[index].Content = child
+	tabs.Refresh()
+}
+
+func accordionItemIndex(acc *widget.Accordion, index int) int {
+	return itemIndex(index, len(acc.Items), func(i int) bool {
+		return acc.Items[i].Detail != nil
+	})
+}
+
+func insertAccordionChild(parent fyne.CanvasObject, index int, child fyne.CanvasObject, _ Context) {
+	acc := parent.(*widget.Accordion)
+	at := accordionItemIndex(acc, index)
+	acc.Items = append(acc.Items, nil)
+	copy(acc.Items[at+1:], acc.Items[at:])
+	acc.Items[at] = widget.NewAccordionItem(fmt.Sprintf("Item %d", len(acc.Items)), child)
+	acc.Refresh()
+}
+
+func removeAccordionChild(parent fyne.CanvasObject, index int, _ Context) {
+	acc := parent.(*widget.Accordion)
+	acc.RemoveIndex(accordionItemIndex(acc, index))
+}
+
+func replaceAccordionChild(parent fyne.CanvasObject, index int, child fyne.CanvasObject, _ Context) {
+	acc := parent.(*widget.Accordion)
+	acc.Items[accordionItemIndex(acc, index)].Detail = child
+	acc.Refresh()
+}
+
+func formItemIndex(form *widget.Form, index int) int {
+	return itemIndex(index, len(form.Items), func(i int) bool {
+		return form.Items[i].Widget != nil
+	})
+}
+
+func insertFormChild(parent fyne.CanvasObject, index int, child fyne.CanvasObject, _ Context) {
+	form := parent.(*widget.Form)
+	at := formItemIndex(form, index)
+	form.Items = append(form.Items, nil)
+	copy(form.Items[at+1:], form.Items[at:])
+	form.Items[at] = widget.NewFormItem("Label", child)
+	form.Refresh()
+}
+
+func removeFormChild(parent fyne.CanvasObject, index int, _ Context) {
+	form := parent.(*widget.Form)
+	at := formItemIndex(form, index)
+	form.Items = append(form.Items[:at], form.Items[at+1:]...)
+	form.Refresh()
+}
+
+func replaceFormChild(parent fyne.CanvasObject, index int, child fyne.CanvasObject, _ Context) {
+	form := parent.(*widget.Form)
+	form.Items[formItemIndex(form, index)].Widget = child
+	form.Refresh()
+}
@@ -41,10 +41,12 @@ func escapeLabel(inStr string) (outStr string) {
 	return
 }
 
-func newIconSelectorButton(ic fyne.Resource, fn func(fyne.Resource), showName bool) (iconSel *widget.Button) {
-	items := make([]*fyne.MenuItem, len(IconNames)+1)
+func newIconSelectorButton(ic fyne.Resource, c Context, fn func(fyne.Resource), showName bool) (iconSel *widget.Button) {
+	custom := ResourceNames(c)
+	icons := RegistryOf(c).IconNames()
+	items := make([]*fyne.MenuItem, 0, len(custom)+len(icons)+2)
 
-	items[0] = &fyne.MenuItem{
+	items = append(items, &fyne.MenuItem{
 		Label: noIconLabel,
 		Icon:  nil,
 		Action: func() {
@@ -52,22 +54,31 @@ func newIconSelectorButton(ic fyne.Resource, fn func(fyne.Resource), showName bo
 			iconSel.SetIcon(nil)
 			fn(nil)
 		},
-	}
-	for i, n := range IconNames {
-		name := n
-		items[i+1] = &fyne.MenuItem{
-			Label: n,
-			Icon:  Icons[n],
+	})
+	addItem := func(name string) {
+		res := LookupResource(name, c)
+		items = append(items, &fyne.MenuItem{
+			Label: name,
+			Icon:  res,
 			Action: func() {
 				if showName {
 					iconSel.SetText(name)
 				} else {
 					iconSel.SetText("")
 				}
-				iconSel.SetIcon(Icons[name])
-				fn(Icons[name])
+				iconSel.SetIcon(res)
+				fn(res)
 			},
-		}
+		})
+	}
+	for _, n := range custom {
+		addItem(n)
+	}
+	if len(custom) > 0 {
+		items = append(items, fyne.NewMenuItemSeparator())
+	}
+	for _, n := range icons {
+		addItem(n)
 	}
 	iconSel = widget.NewButton(noIconLabel, func() {
 		d := fyne.CurrentApp().Driver()
@@ -76,17 +87,14 @@ func newIconSelectorButton(ic fyne.Resource, fn func(fyne.Resource), showName bo
 		widget.NewPopUpMenu(fyne.NewMenu("", items...), c).ShowAtPosition(p)
 	})
 	if ic != nil {
-		name := IconName(ic)
-		for _, n := range IconNames {
-			if n == name {
-				if showName {
-					iconSel.SetText(n)
-				} else {
-					iconSel.SetText("")
-				}
-				iconSel.SetIcon(Icons[n])
-				break
+		name := ResourceName(ic, c)
+		if res := LookupResource(name, c); res != nil {
+			if showName {
+				iconSel.SetText(name)
+			} else {
+				iconSel.SetText("")
 			}
+			iconSel.SetIcon(res)
 		}
 	}
 
@@ -31,6 +31,18 @@ type Context interface {
 	Root() fyne.CanvasObject
 }
 
+// RegistryContext is a context that resolves types using its own registry rather than the default one.
+type RegistryContext interface {
+	// Registry returns the types that this context can use, if it is nil the default registry is used.
+	Registry() *Registry
+}
+
+// ResourceContext is a context that has custom resources, which the GUI can use in addition to the theme icons.
+type ResourceContext interface {
+	// Resources returns the custom resources that the GUI can use, keyed by name.
+	Resources() map[string]Resource
+}
+
 var (
 	// WidgetNames is an array with the list of names of all the Widgets
 	WidgetNames []string
@@ -59,6 +71,13 @@ type WidgetInfo struct {
 	Edit     func(fyne.CanvasObject, Context, func([]*widget.FormItem), func()) []*widget.FormItem
 	Gostring func(fyne.CanvasObject, Context, map[string]string) string
 	Packages func(fyne.CanvasObject, Context) []string
+
+	// InsertChild adds a child at an index of the Children list, it is nil if the children are fixed
+	InsertChild func(parent fyne.CanvasObject, index int, child fyne.CanvasObject, c Context)
+	// RemoveChild removes the child at an index of the Children list, it is nil if the children are fixed
+	RemoveChild func(parent fyne.CanvasObject, index int, c Context)
+	// ReplaceChild sets the child at an index of the Children list
+	ReplaceChild func(parent fyne.CanvasObject, index int, child fyne.CanvasObject, c Context)
 }
 
 // IsContainer indicates whether a widget children or not
@@ -71,7 +90,6 @@ func initWidgets() {
 		"*widget.Activity":   initActivityWidget(),
 		"*widget.Button":     initButtonWidget(),
 		"*widget.Hyperlink":  initHyperlinkWidget(),
-		"*widget.Card":       initCardWidget(),
 		"*widget.Entry":      initEntryWidget(),
 		"*widget.Icon":       initIconWidget(),
 		"*widget.Label":      initLabelWidget(),
@@ -95,8 +113,6 @@ func initWidgets() {
 			},
 		},
 		"*widget.DateEntry": initDateEntryWidget(),
-		"*widget.Accordion": initAccordionWidget(),
-		"*widget.Form":      initFormWidget(),
 		"*widget.MultiLineEntry": {
 			Name: "Multi Line Entry",
 			Create: func(Context) fyne.CanvasObject {
@@ -137,144 +153,7 @@ func initWidgets() {
 		"*xWidget.Map":     initMapXWidget(),
 	}
 
-	Collections = map[string]WidgetInfo{
-		"*widget.List": {
-			Name: "List",
-			Create: func(Context) fyne.CanvasObject {
-				return widget.NewList(func() int {
-					return 5
-				}, func() fyne.CanvasObject {
-					return widget.NewLabel("Template Object")
-				}, func(id widget.ListItemID, item fyne.CanvasObject) {
-					item.(*widget.Label).SetText(fmt.Sprintf("Item %d", id+1))
-				})
-			},
-			Edit: func(obj fyne.CanvasObject, _ Context, _ func([]*widget.FormItem), _ func()) []*widget.FormItem {
-				return []*widget.FormItem{}
-			},
-			Gostring: func(obj fyne.CanvasObject, c Context, defs map[string]string) string {
-				return widgetRef(obj, c, defs,
-					`widget.NewList(func() int {
-				return 5
-			}, func() fyne.CanvasObject {
-				return widget.NewLabel("Template Object")
-			}, func(id widget.ListItemID, item fyne.CanvasObject) {
-				item.(*widget.Label).SetText(fmt.Sprintf("Item %d", id+1))
-			})`)
-			},
-			Packages: func(obj fyne.CanvasObject, c Context) []string {
-				pkgs := []string{"widget", "fmt"}
-				if c.Metadata()[obj]["Data"] != "" {
-					return append(pkgs, "fyne.io/fyne/v2/data/binding")
-				}
-				return pkgs
-			},
-		},
-		"*widget.Table": {
-			Name: "Table",
-			Create: func(Context) fyne.CanvasObject {
-				return widget.NewTable(func() (int, int) {
-					return 3, 3
-				}, func() fyne.CanvasObject {
-					return widget.NewLabel("Cell 000, 000")
-				}, func(id widget.TableCellID, cell fyne.CanvasObject) {
-					label := cell.(*widget.Label)
-					label.SetText(fmt.Sprintf("Cell %d, %d", id.Row+1, id.Col+1))
-				})
-			},
-			Edit: func(obj fyne.CanvasObject, _ Context, _ func([]*widget.FormItem), _ func()) []*widget.FormItem {
-				return []*widget.FormItem{}
-			},
-			Gostring: func(obj fyne.CanvasObject, c Context, defs map[string]string) string {
-				return widgetRef(obj, c, defs,
-					`widget.NewTable(func() (int, int) {
-				return 3, 3
-			}, func() fyne.CanvasObject {
-				return widget.NewLabel("Cell 000, 000")
-			}, func(id widget.TableCellID, cell fyne.CanvasObject) {
-				label := cell.(*widget.Label)
-				label.SetText(fmt.Sprintf("Cell %d, %d", id.Row+1, id.Col+1))
-			})`)
-			},
-			Packages: func(obj fyne.CanvasObject, _ Context) []string {
-				return []string{"widget", "fmt"}
-			},
-		},
-		"*widget.Tree": {
-			Name: "Tree",
-			Create: func(Context) fyne.CanvasObject {
-				data := map[string][]string{
-					"":  {"A"},
-					"A": {"B", "D", "H", "J", "L", "O", "P", "S", "V"},
-					"B": {"C"},
-					"C": {"abc"},
-					"D": {"E"},
-					"E": {"F", "G"},
-					"F": {"adef"},
-					"G": {"adeg"},
-					"H": {"I"},
-					"I": {"ahi"},
-					"O": {"ao"},
-					"P": {"Q"},
-					"Q": {"R"},
-					"R": {"apqr"},
-					"S": {"T"},
-					"T": {"U"},
-					"U": {"astu"},
-					"V": {"W"},
-					"W": {"X"},
-					"X": {"Y"},
-					"Y": {"Z"},
-					"Z": {"avwxyz"},
-				}
-
-				tree := widget.NewTreeWithStrings(data)
-				tree.OnSelected = func(id string) {
-					fmt.Println("Tree node selected:", id)
-				}
-				tree.OnUnselected = func(id string) {
-					fmt.Println("Tree node unselected:", id)
-				}
-				tree.OpenBranch("A")
-				tree.OpenBranch("D")
-				tree.OpenBranch("E")
-				tree.OpenBranch("L")
-				tree.OpenBranch("M")
-				return tree
-			},
-			Edit: func(co fyne.CanvasObject, _ Context, _ func([]*widget.FormItem), _ func()) []*widget.FormItem {
-				return []*widget.FormItem{}
-			},
-			Gostring: func(obj fyne.CanvasObject, c Context, defs map[string]string) string {
-				return widgetRef(obj, c, defs,
-					`widget.NewTreeWithStrings(
-map[string][]string{
-	"":  {"A"},
-	"A": {"B", "D", "H", "J", "L", "O", "P", "S", "V"},
-	"B": {"C"},
-	"C": {"abc"},
-	"D": {"E"},
-	"E": {"F", "G"},
-	"F": {"adef"},
-	"G": {"adeg"},
-	"H": {"I"},
-	"I": {"ahi"},
-	"O": {"ao"},
-	"P": {"Q"},
-	"Q": {"R"},
-	"R": {"apqr"},
-	"S": {"T"},
-	"T": {"U"},
-	"U": {"astu"},
-	"V": {"W"},
-	"W": {"X"},
-	"X": {"Y"},
-	"Y": {"Z"},
-	"Z": {"avwxyz"},
-})`)
-			},
-		},
-	}
+	Collections = initCollections()
 
 	WidgetNames = extractNames(Widgets)
 	CollectionNames = extractNames(Collections)
@@ -283,6 +162,25 @@ map[string][]string{
 func initAccordionWidget() WidgetInfo {
 	return WidgetInfo{
 		Name: "Accordion",
+		Children: func(o fyne.CanvasObject) []fyne.CanvasObject {
+			acc := o.(*widget.Accordion)
+
+			children := make([]fyne.CanvasObject, 0, len(acc.Items))
+			for _, i := range acc.Items {
+				if i.Detail != nil {
+					children = append(children, i.Detail)
+				}
+			}
+			return children
+		},
+		AddChild: func(parent, o fyne.CanvasObject) {
+			acc := parent.(*widget.Accordion)
+
+			acc.Append(widget.NewAccordionItem(fmt.Sprintf("Item %d", len(acc.Items)+1), o))
+		},
+		InsertChild:  insertAccordionChild,
+		RemoveChild:  removeAccordionChild,
+		ReplaceChild: replaceAccordionChild,
 		Create: func(Context) fyne.CanvasObject {
 			return widget.NewAccordion(widget.NewAccordionItem("Item 1", widget.NewLabel("The content goes here")), widget.NewAccordionItem("Item 2", widget.NewLabel("Content part 2 goes here")))
 		},
@@ -300,15 +198,30 @@ func initAccordionWidget() WidgetInfo {
 			return []*widget.FormItem{widget.NewFormItem("Multiple Open", multi)}
 		},
 		Gostring: func(obj fyne.CanvasObject, c Context, defs map[string]string) string {
-			items := "widget.NewAccordionItem(\"Item 1\", widget.NewLabel(\"The content goes here\")), widget.NewAccordionItem(\"Item 2\", widget.NewLabel(\"Content part 2 goes here\"))"
 			acc := obj.(*widget.Accordion)
+			str := &strings.Builder{}
+			for i, item := range acc.Items {
+				if i > 0 {
+					str.WriteString(", ")
+				}
+				if item.Open {
+					str.WriteString("&widget.AccordionItem{Title: \"" + escapeLabel(item.Title) + "\", Detail: ")
+					writeGoStringOrNil(str, c, defs, item.Detail)
+					str.WriteString(", Open: true}")
+					continue
+				}
+				str.WriteString("widget.NewAccordionItem(\"" + escapeLabel(item.Title) + "\", ")
+				writeGoStringOrNil(str, c, defs, item.Detail)
+				str.WriteString(")")
+			}
+
 			if acc.MultiOpen {
 				return widgetRef(obj, c, defs,
-					fmt.Sprintf("&widget.Accordion{Items: []*widget.AccordionItem{%s}, MultiOpen: true}", items))
+					fmt.Sprintf("&widget.Accordion{Items: []*widget.AccordionItem{%s}, MultiOpen: true}", str.String()))
 			}
 
 			return widgetRef(obj, c, defs,
-				fmt.Sprintf("widget.NewAccordion(%s)", items))
+				fmt.Sprintf("widget.NewAccordion(%s)", str.String()))
 		},
 	}
 }
@@ -336,7 +249,7 @@ func initButtonWidget() WidgetInfo {
 		Create: func(Context) fyne.CanvasObject {
 			return widget.NewButton("Button", func() {})
 		},
-		Edit: func(obj fyne.CanvasObject, _ Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
+		Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
 			b := obj.(*widget.Button)
 			entry := widget.NewEntry()
 			entry.SetText(b.Text)
@@ -397,7 +310,7 @@ func initButtonWidget() WidgetInfo {
 			ready = true
 			return []*widget.FormItem{
 				widget.NewFormItem("Text", entry),
-				widget.NewFormItem("Icon", newIconSelectorButton(b.Icon, b.SetIcon, true)),
+				widget.NewFormItem("Icon", newIconSelectorButton(b.Icon, c, b.SetIcon, true)),
 				widget.NewFormItem("Importance", importance),
 				widget.NewFormItem("Alignment", aligns),
 			}
@@ -411,7 +324,7 @@ func initButtonWidget() WidgetInfo {
 				attrs = append(attrs, "OnTapped = "+fn)
 			}
 			if b.Icon != nil {
-				attrs = append(attrs, "Icon = theme."+IconName(b.Icon)+"()")
+				attrs = append(attrs, "Icon = "+ResourceCode(b.Icon, c))
 			}
 			if b.Importance != widget.MediumImportance {
 				attrs = append(attrs, fmt.Sprintf("Importance = %d", b.Importance))
@@ -423,13 +336,9 @@ func initButtonWidget() WidgetInfo {
 
 			return widgetRef(obj, c, defs, fmt.Sprintf("widget.NewButton(%q, nil)", b.Text))
 		},
-		Packages: func(obj fyne.CanvasObject, _ Context) []string {
+		Packages: func(obj fyne.CanvasObject, c Context) []string {
 			b := obj.(*widget.Button)
-			if b.Icon == nil {
-				return []string{"widget"}
-			}
-
-			return []string{"widget", "theme"}
+			return append([]string{"widget"}, resourcePackages(b.Icon, c)...)
 		},
 	}
 }
@@ -437,6 +346,23 @@ func initButtonWidget() WidgetInfo {
 func initCardWidget() WidgetInfo {
 	return WidgetInfo{
 		Name: "Card",
+		Children: func(o fyne.CanvasObject) []fyne.CanvasObject {
+			c := o.(*widget.Card)
+			if c.Content == nil {
+				return []fyne.CanvasObject{}
+			}
+			return []fyne.CanvasObject{c.Content}
+		},
+		AddChild: func(parent, o fyne.CanvasObject) {
+			c := parent.(*widget.Card)
+			c.SetContent(o)
+		},
+		RemoveChild: func(parent fyne.CanvasObject, _ int, _ Context) {
+			parent.(*widget.Card).SetContent(nil)
+		},
+		ReplaceChild: func(parent fyne.CanvasObject, _ int, o fyne.CanvasObject, _ Context) {
+			parent.(*widget.Card).SetContent(o)
+		},
 		Create: func(Context) fyne.CanvasObject {
 			return widget.NewCard("Title", "Subtitle", widget.NewLabel("Content here"))
 		},
@@ -461,8 +387,11 @@ func initCardWidget() WidgetInfo {
 		},
 		Gostring: func(obj fyne.CanvasObject, ctx Context, defs map[string]string) string {
 			c := obj.(*widget.Card)
-			return widgetRef(obj, ctx, defs, fmt.Sprintf("widget.NewCard(\"%s\", \"%s\", widget.NewLabel(\"Content here\"))",
-				escapeLabel(c.Title), escapeLabel(c.Subtitle)))
+			str := &strings.Builder{}
+			str.WriteString(fmt.Sprintf("widget.NewCard(\"%s\", \"%s\", ", escapeLabel(c.Title), escapeLabel(c.Subtitle)))
+			writeGoStringOrNil(str, ctx, defs, c.Content)
+			str.WriteString(")")
+			return widgetRef(obj, ctx, defs, str.String())
 		},
 	}
 }
@@ -497,6 +426,10 @@ func initCheckWidget() WidgetInfo {
 		},
 		Gostring: func(obj fyne.CanvasObject, ctx Context, defs map[string]string) string {
 			c := obj.(*widget.Check)
+			if data := Binding(obj, ctx); data != "" {
+				return widgetRef(obj, ctx, defs,
+					fmt.Sprintf("widget.NewCheckWithData(\"%s\", g.%s)", escapeLabel(c.Text), data))
+			}
 			return widgetRef(obj, ctx, defs,
 				fmt.Sprintf("widget.NewCheck(\"%s\", func(b bool) {})", escapeLabel(c.Text)))
 		},
@@ -559,7 +492,8 @@ func initEntryWidget() WidgetInfo {
 					attrs = append(attrs, on+" = "+props[on])
 				}
 			}
-			if l.Text != "" {
+			data := Binding(obj, c)
+			if l.Text != "" && data == "" {
 				attrs = append(attrs, fmt.Sprintf("Text = %q", l.Text))
 			}
 			if l.PlaceHolder != "" {
@@ -573,6 +507,9 @@ func initEntryWidget() WidgetInfo {
 			}
 			c.Attrs()[obj] = attrs
 
+			if data != "" {
+				return widgetRef(obj, c, defs, "widget.NewEntryWithData(g."+data+")")
+			}
 			return widgetRef(obj, c, defs, "widget.NewEntry()")
 		},
 	}
@@ -581,6 +518,25 @@ func initEntryWidget() WidgetInfo {
 func initFormWidget() WidgetInfo {
 	return WidgetInfo{
 		Name: "Form",
+		Children: func(o fyne.CanvasObject) []fyne.CanvasObject {
+			form := o.(*widget.Form)
+
+			children := make([]fyne.CanvasObject, 0, len(form.Items))
+			for _, i := range form.Items {
+				if i.Widget != nil {
+					children = append(children, i.Widget)
+				}
+			}
+			return children
+		},
+		AddChild: func(parent, o fyne.CanvasObject) {
+			form := parent.(*widget.Form)
+
+			form.Append("Label", o)
+		},
+		InsertChild:  insertFormChild,
+		RemoveChild:  removeFormChild,
+		ReplaceChild: replaceFormChild,
 		Create: func(Context) fyne.CanvasObject {
 			f := widget.NewForm(widget.NewFormItem("Username", widget.NewEntry()), widget.NewFormItem("Password", widget.NewPasswordEntry()), widget.NewFormItem("Remember", widget.NewCheck("", func(bool) {})))
 			f.OnSubmit = func() {}
@@ -657,9 +613,9 @@ func initFormWidget() WidgetInfo {
 					}
 
 					class := "*widget." + insertChose
-					wid1 := Lookup(class).Create(c)
+					wid1 := RegistryOf(c).Lookup(class).Create(c)
 					tidyWidget(wid1, wid1)
-					wid2 := Lookup(class).Create(c)
+					wid2 := RegistryOf(c).Lookup(class).Create(c)
 					tidyWidget(wid2, wid2)
 
 					obj.(*widget.Form).Items = nil // TODO fix this too!
@@ -718,7 +674,7 @@ func initFormWidget() WidgetInfo {
 			for _, o := range formItems {
 				// copy items so the widgets can be in both places
 				class := reflect.TypeOf(o.Widget).String()
-				wid := Lookup(class).Create(c)
+				wid := RegistryOf(c).Lookup(class).Create(c)
 				tidyWidget(wid, o.Widget)
 
 				var row *fyne.Container
@@ -743,8 +699,8 @@ func initFormWidget() WidgetInfo {
 			str := &strings.Builder{}
 			str.WriteString("&widget.Form{Items: []*widget.FormItem{")
 			for _, i := range form.Items {
-				str.WriteString("widget.NewFormItem(\"" + i.Text + "\", ")
-				writeGoStringExcluding(str, nil, c, defs, i.Widget)
+				str.WriteString("widget.NewFormItem(\"" + escapeLabel(i.Text) + "\", ")
+				writeGoStringOrNil(str, c, defs, i.Widget)
 				str.WriteString("),")
 			}
 			str.WriteString("}")
@@ -800,10 +756,10 @@ func initIconWidget() WidgetInfo {
 		Create: func(Context) fyne.CanvasObject {
 			return widget.NewIcon(theme.HelpIcon())
 		},
-		Edit: func(obj fyne.CanvasObject, _ Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
+		Edit: func(obj fyne.CanvasObject, c Context, _ func([]*widget.FormItem), onchanged func()) []*widget.FormItem {
 			i := obj.(*widget.Icon)
 			return []*widget.FormItem{
-				widget.NewFormItem("Icon", newIconSelectorButton(i.Resource, func(res fyne.Resource) {
+				widget.NewFormItem("Icon", newIconSelectorButton(i.Resource, c, func(res fyne.Resource) {
 					i.SetResource(res)
 					onchanged()
 				}, true)),
@@ -812,11 +768,15 @@ func initIconWidget() WidgetInfo {
 		Gostring: func(obj fyne.CanvasObject, c Context, defs map[string]string) string {
 			i := obj.(*widget.Icon)
 
-			res := "theme." + IconName(i.Resource) + "()"
+			res := "nil"
+			if i.Resource != nil {
+				res = ResourceCode(i.Resource, c)
+			}
 			return widgetRef(obj, c, defs, fmt.Sprintf("widget.NewIcon(%s)", res))
 		},
-		Packages: func(obj fyne.CanvasObject, _ Context) []string {
-			return []string{"widget", "theme"}
+		Packages: func(obj fyne.CanvasObject, c Context) []string {
+			i := obj.(*widget.Icon)
+			return append([]string{"widget"}, resourcePackages(i.Resource, c)...)
 		},
 	}
 }
@@ -912,6 +872,22 @@ func initLabelWidget() WidgetInfo {
 		},
 		Gostring: func(obj fyne.CanvasObject, c Context, defs map[string]string) string {
 			l := obj.(*widget.Label)
+			if data := Binding(obj, c); data != "" {
+				attrs := c.Attrs()[obj]
+				if l.Alignment != fyne.TextAlignLeading {
+					attrs = append(attrs, fmt.Sprintf("Alignment = %d", l.Alignment))
+				}
+				if l.Wrapping != fyne.TextWrapOff {
+					attrs = append(attrs, fmt.Sprintf("Wrapping = %d", l.Wrapping))
+				}
+				if l.TextStyle.Bold || l.TextStyle.Italic || l.TextStyle.Monospace {
+					attrs = append(attrs, fmt.Sprintf("TextStyle = %#v", l.TextStyle))
+				}
+				c.Attrs()[obj] = attrs
+
+				return widgetRef(obj, c, defs, "widget.NewLabelWithData(g."+data+")")
+			}
+
 			if l.Alignment != fyne.TextAlignLeading || l.Wrapping != fyne.TextWrapOff {
 				styles := []string{}
 				if l.TextStyle.Bold {
@@ -972,6 +948,9 @@ func initProgressBarWidget() WidgetInfo {
 		},
 		Gostring: func(obj fyne.CanvasObject, c Context, defs map[string]string) string {
 			p := obj.(*widget.ProgressBar)
+			if data := Binding(obj, c); data != "" {
+				return widgetRef(obj, c, defs, "widget.NewProgressBarWithData(g."+data+")")
+			}
 			return widgetRef(obj, c, defs,
 				fmt.Sprintf("&widget.ProgressBar{Value: %f}", p.Value))
 		},
@@ -1179,6 +1158,12 @@ func initSliderWidget() WidgetInfo {
 			if slider.Orientation == widget.Vertical {
 				orient = "widget.Vertical"
 			}
+			if data := Binding(obj, c); data != "" {
+				if slider.Orientation == widget.Vertical {
+					c.Attrs()[obj] = append(c.Attrs()[obj], "Orientation = "+orient)
+				}
+				return widgetRef(obj, c, defs, "widget.NewSliderWithData(0, 100, g."+data+")")
+			}
 			return widgetRef(obj, c, defs, fmt.Sprintf("&widget.Slider{Min:0, Max:100, Value:%f, Orientation: %s}", slider.Value, orient))
 		},
 	}
@@ -1226,7 +1211,7 @@ func initToolbarWidget() WidgetInfo {
 				widget.NewToolbarAction(Icons["HelpIcon"], func() { fmt.Println("Clicked on HelpIcon") }),
 			)
 		},
-		Edit: func(obj fyne.CanvasObject, _ Context, refresh func([]*widget.FormItem), _ func()) []*widget.FormItem {
+		Edit: func(obj fyne.CanvasObject, c Context, refresh func([]*widget.FormItem), _ func()) []*widget.FormItem {
 			items := []*widget.FormItem{}
 			toolItems := obj.(*widget.Toolbar).Items
 
@@ -1254,7 +1239,7 @@ func initToolbarWidget() WidgetInfo {
 					chosen = options[2]
 				case *widget.ToolbarAction:
 					chosen = options[0]
-					wid = newIconSelectorButton(t.Icon, t.SetIcon, false)
+					wid = newIconSelectorButton(t.Icon, c, t.SetIcon, false)
 					holder.Objects = []fyne.CanvasObject{wid}
 				}
 
@@ -1273,7 +1258,7 @@ func initToolbarWidget() WidgetInfo {
 						toolItems[id] = act
 						items[id].Text = "Action"
 
-						holder.Objects = []fyne.CanvasObject{newIconSelectorButton(act.Icon, act.SetIcon, false)}
+						holder.Objects = []fyne.CanvasObject{newIconSelectorButton(act.Icon, c, act.SetIcon, false)}
 					}
 
 					obj.Refresh()
@@ -1319,7 +1304,7 @@ func initToolbarWidget() WidgetInfo {
 				case *widget.ToolbarSpacer:
 					str.WriteString("\t\t\t\twidget.NewToolbarSpacer(),\n")
 				case *widget.ToolbarAction:
-					res := "theme." + IconName(t.Icon) + "()"
+					res := ResourceCode(t.Icon, c)
 					str.WriteString(fmt.Sprintf("\t\t\t\twidget.NewToolbarAction(%s, func() {}),\n", res))
 					// TODO action handler
 				}
@@ -1327,6 +1312,15 @@ func initToolbarWidget() WidgetInfo {
 			str.WriteString(")")
 			return widgetRef(obj, c, defs, str.String())
 		},
+		Packages: func(obj fyne.CanvasObject, c Context) []string {
+			pkgs := []string{"widget"}
+			for _, i := range obj.(*widget.Toolbar).Items {
+				if act, ok := i.(*widget.ToolbarAction); ok {
+					pkgs = appendPackages(pkgs, resourcePackages(act.Icon, c)...)
+				}
+			}
+			return pkgs
+		},
 	}
 }
 
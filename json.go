@@ -3,17 +3,17 @@ package refyne
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"image/color"
 	"io"
-	"log"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
@@ -25,7 +25,7 @@ type canvObj struct {
 	Type       string
 	Name       string            `json:",omitempty"`
 	Actions    map[string]string `json:",omitempty"`
-	Struct     fyne.CanvasObject `json:",omitempty"`
+	Struct     *structValue      `json:",omitempty"`
 	Properties map[string]string `json:",omitempty"`
 }
 
@@ -43,7 +43,7 @@ type form struct {
 
 type formItem struct {
 	HintText, Text string
-	Widget         *canvObj
+	Widget         interface{}
 }
 
 type cont struct {
@@ -54,9 +54,55 @@ type cont struct {
 	Properties map[string]string `json:",omitempty"`
 }
 
+// rawJSONKey is the metadata key used to store the original JSON of an object that could not be decoded.
+const rawJSONKey = "raw-json"
+
+// DecodeOptions configures how `DecodeObjectWithOptions` and `DecodeMapWithOptions` handle their data.
+type DecodeOptions struct {
+	// Lenient replaces any object that cannot be decoded with a placeholder label instead of dropping it.
+	// The original JSON is kept in the placeholder metadata and written out unchanged by `EncodeObject`,
+	// so that files using unknown widgets can be loaded and saved without losing data.
+	// Problems that resulted in a placeholder are reported as warnings.
+	Lenient bool
+
+	// Progress, if set, is called by `DecodeObjectStream` each time an object has been created.
+	Progress func(DecodeProgress)
+
+	// LoadComponent, if set, opens the GUI document that a "Component" node refers to.
+	// The reference is passed unchanged, including for components that are inside other components.
+	// By default the reference is a file path, which may leave out the ".gui.json" extension.
+	// Relative paths are found from the directory of the document that contains the reference,
+	// or from `Dir` for references in the document being decoded.
+	LoadComponent func(ref string) (io.ReadCloser, error)
+
+	// Dir is the directory of the document being decoded, used to find components by relative path.
+	// If it is empty the working directory is used.
+	Dir string
+}
+
+// EncodeOptions configures how `EncodeObjectWithOptions` writes a document.
+type EncodeOptions struct {
+	// Canonical writes the same bytes for the same tree every time, so that files can be compared.
+	// Keys are sorted, fields that hold the zero value of their type are left out
+	// and numbers are written in their shortest form.
+	// The document is marked as canonical so that left out fields are decoded as zero values,
+	// rather than taking the defaults of a newly created object.
+	Canonical bool
+
+	expandComponent bool // the root is written in full, even if it is a component, to save the component document
+}
+
 // DecodeObject returns a tree of `CanvasObject` elements from the provided JSON `Reader` and
 // updates the metadata map to include any additional information.
+// Documents from older format versions are upgraded using the registered migrations before decoding.
+// If the data contained problems a `*DecodeError` is returned listing each of them, along with
+// the parts of the tree that could be decoded.
 func DecodeObject(r io.Reader, d Context) (fyne.CanvasObject, error) {
+	return DecodeObjectWithOptions(r, d, DecodeOptions{})
+}
+
+// DecodeObjectWithOptions is like `DecodeObject` but allows the decoding behaviour to be configured.
+func DecodeObjectWithOptions(r io.Reader, d Context, opts DecodeOptions) (fyne.CanvasObject, error) {
 	guidefs.InitOnce()
 
 	var data interface{}
@@ -65,87 +111,96 @@ func DecodeObject(r io.Reader, d Context) (fyne.CanvasObject, error) {
 		return nil, err
 	}
 
-	obj, err := DecodeMap(data.(map[string]interface{}), d)
-	return obj, err
+	return decodeDocument(data, d, opts)
+}
+
+// decodeDocument returns the tree of `CanvasObject` elements for a document that has been parsed into maps,
+// slices and basic types in the same way as encoding/json.
+func decodeDocument(data interface{}, d Context, opts DecodeOptions) (fyne.CanvasObject, error) {
+	dec := &decoder{ctx: d, opts: opts, dir: opts.Dir}
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		dec.report("", "", SeverityError, "document root is not an object")
+		return nil, dec.err()
+	}
+
+	root := dec.upgradeDocument(m)
+	if root == nil {
+		return nil, dec.err()
+	}
+
+	obj := dec.decodeMap(root, "")
+	return obj, dec.err()
 }
 
 // DecodeMap returns a tree of `CanvasObject` elements from the provided JSON map and
 // updates the metadata map to include any additional information.
+// If the data contained problems a `*DecodeError` is returned listing each of them, along with
+// the parts of the tree that could be decoded.
 func DecodeMap(m map[string]interface{}, d Context) (fyne.CanvasObject, error) {
+	return DecodeMapWithOptions(m, d, DecodeOptions{})
+}
+
+// DecodeMapWithOptions is like `DecodeMap` but allows the decoding behaviour to be configured.
+func DecodeMapWithOptions(m map[string]interface{}, d Context, opts DecodeOptions) (fyne.CanvasObject, error) {
 	guidefs.InitOnce()
 
-	switch m["Type"] {
-	case "*fyne.Container":
-		obj := &fyne.Container{}
-		name := "WithoutLayout"
-		if n, ok := m["Layout"]; ok {
-			name = n.(string)
-		}
+	dec := &decoder{ctx: d, opts: opts, dir: opts.Dir}
+	obj := dec.decodeMap(m, "")
+	return obj, dec.err()
+}
 
-		props := map[string]string{"layout": name}
-		d.Metadata()[obj] = props
-		if m["Properties"] != nil {
-			for k, v := range m["Properties"].(map[string]interface{}) {
-				props[k] = v.(string)
-			}
-		}
-		if name == "HBox" {
-			props["dir"] = "horizontal"
-		} else if name == "VBox" {
-			props["dir"] = "vertical"
-		}
+type decoder struct {
+	ctx    Context
+	opts   DecodeOptions
+	issues []DecodeIssue
 
-		if m["Objects"] != nil {
-			for _, data := range m["Objects"].([]interface{}) {
-				if data == nil {
-					// Nil object?
-					continue
-				}
-				child, _ := DecodeMap(data.(map[string]interface{}), d)
-				if child != nil {
-					obj.Objects = append(obj.Objects, child)
-				}
-			}
-		}
-		layoutType, ok := guidefs.Layouts[name]
-		if !ok {
-			log.Println("Undefined layout,", name)
-			layoutType = guidefs.Layouts["Stack"]
-		}
-		obj.Layout = layoutType.Create(obj, d)
-		if name, ok := m["Name"]; ok {
-			props["name"] = name.(string)
-		}
+	loading   []string // the components being decoded, to detect a component that includes itself
+	dir       string   // the directory that relative component paths are found from
+	canonical bool     // fields missing from the document hold their zero value
+}
 
-		return obj, nil
+func (dec *decoder) err() error {
+	if len(dec.issues) == 0 {
+		return nil
+	}
+
+	return &DecodeError{Issues: dec.issues}
+}
+
+func (dec *decoder) report(path, class string, sev Severity, msg string) {
+	dec.issues = append(dec.issues, DecodeIssue{Path: path, Severity: sev, Type: class, Message: msg})
+}
+
+func (dec *decoder) decodeMap(m map[string]interface{}, path string) fyne.CanvasObject {
+	switch m["Type"] {
+	case "*fyne.Container":
+		return dec.decodeContainer(m, path)
 	case "*container.AppTabs":
-		return decodeAppTabs(m, d)
+		return dec.decodeAppTabs(m, path)
 	case "*container.Clip":
-		return decodeClip(m, d)
+		return dec.decodeClip(m, path)
 	case "*container.Navigation":
-		return decodeNavigation(m, d)
+		return dec.decodeNavigation(m, path)
 	case "*container.Scroll":
-		return decodeScroll(m, d)
+		return dec.decodeScroll(m, path)
 	case "*container.Split":
-		return decodeSplit(m, d)
+		return dec.decodeSplit(m, path)
 	case "*container.ThemeOverride":
-		return decodeThemeOverride(m, d)
+		return dec.decodeThemeOverride(m, path)
+	case componentType:
+		return dec.decodeComponent(m, path)
 	}
 
-	obj := decodeWidget(m, d)
-	if obj == nil {
-		return nil, errors.New("failed to parse object from JSON")
+	obj := dec.decodeWidget(m, path)
+	if obj == nil || dec.ctx.Metadata()[obj][rawJSONKey] != "" {
+		return obj
 	}
 	obj.Refresh()
+	class, _ := m["Type"].(string)
 	props := map[string]string{}
-	if unpacked, ok := m["Properties"].(map[string]interface{}); ok {
-		for k, v := range unpacked {
-			props[k] = v.(string)
-		}
-	}
-	if name, ok := m["Name"]; ok {
-		props["name"] = name.(string)
-	}
+	dec.decodeProperties(m, path, class, props)
+	dec.decodeName(m, path, class, props)
 
 	if setMin, ok := obj.(interface{ SetMinSize(fyne.Size) }); ok {
 		minWithStr := props["minWidth"]
@@ -158,83 +213,218 @@ func DecodeMap(m map[string]interface{}, d Context) (fyne.CanvasObject, error) {
 		}
 	}
 
-	if set, ok := m["Actions"]; ok {
+	if set, ok := m["Actions"]; ok && set != nil {
 		if actions, ok := set.(map[string]any); ok {
 			for k, v := range actions {
-				props[k] = v.(string)
+				s, ok := v.(string)
+				if !ok {
+					dec.report(joinPath(path, "Actions."+k), class, SeverityWarning, "action should be a string")
+					continue
+				}
+				props[k] = s
 			}
+		} else {
+			dec.report(joinPath(path, "Actions"), class, SeverityWarning, "Actions should be an object")
 		}
 	}
 
-	d.Metadata()[obj] = props
-	return obj, nil
+	dec.ctx.Metadata()[obj] = props
+	guidefs.ApplyThemeReferences(obj, dec.ctx)
+	guidefs.ApplyColumnWidths(obj, dec.ctx)
+	return obj
+}
+
+// decodeChild decodes the object stored under key in the map m, returning nil if it is not set.
+func (dec *decoder) decodeChild(m map[string]interface{}, key, path string) fyne.CanvasObject {
+	data, ok := m[key]
+	if !ok || data == nil {
+		return nil
+	}
+
+	return dec.decodeValue(data, joinPath(path, key))
+}
+
+func (dec *decoder) decodeValue(data interface{}, path string) fyne.CanvasObject {
+	if built, ok := data.(*decodedObject); ok {
+		return built.obj
+	}
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return dec.placeholder(data, path, "", "expected an object")
+	}
+
+	return dec.decodeMap(m, path)
+}
+
+func (dec *decoder) decodeName(m map[string]interface{}, path, class string, props map[string]string) {
+	name, ok := m["Name"]
+	if !ok || name == nil {
+		return
+	}
+
+	s, ok := name.(string)
+	if !ok {
+		dec.report(joinPath(path, "Name"), class, SeverityWarning, "Name should be a string")
+		return
+	}
+	props["name"] = s
 }
 
-func decodeThemeOverride(m map[string]interface{}, d Context) (fyne.CanvasObject, error) {
-	var content fyne.CanvasObject
-	info := m["Struct"].(map[string]interface{})
-	if info["Content"] != nil {
-		child, _ := DecodeMap(info["Content"].(map[string]interface{}), d)
-		content = child
+func (dec *decoder) decodeProperties(m map[string]interface{}, path, class string, props map[string]string) {
+	data, ok := m["Properties"]
+	if !ok || data == nil {
+		return
+	}
+
+	unpacked, ok := data.(map[string]interface{})
+	if !ok {
+		dec.report(joinPath(path, "Properties"), class, SeverityWarning, "Properties should be an object")
+		return
+	}
+	for k, v := range unpacked {
+		s, ok := v.(string)
+		if !ok {
+			dec.report(joinPath(path, "Properties."+k), class, SeverityWarning, "property should be a string")
+			continue
+		}
+		props[k] = s
+	}
+}
+
+// decodeStruct returns the "Struct" data of a JSON object, or an empty map if it is missing or invalid.
+func (dec *decoder) decodeStruct(m map[string]interface{}, path, class string) map[string]interface{} {
+	data, ok := m["Struct"]
+	if !ok || data == nil {
+		dec.report(path, class, SeverityWarning, "Struct was not found")
+		return map[string]interface{}{}
+	}
+
+	info, ok := data.(map[string]interface{})
+	if !ok {
+		dec.report(joinPath(path, "Struct"), class, SeverityError, "Struct should be an object")
+		return map[string]interface{}{}
+	}
+	return info
+}
+
+func (dec *decoder) decodeContainer(m map[string]interface{}, path string) fyne.CanvasObject {
+	const class = "*fyne.Container"
+	obj := &fyne.Container{}
+	name := "WithoutLayout"
+	if n, ok := m["Layout"]; ok {
+		if s, ok := n.(string); ok {
+			name = s
+		} else {
+			dec.report(joinPath(path, "Layout"), class, SeverityWarning, "Layout should be a string")
+		}
+	}
+
+	props := map[string]string{"layout": name}
+	d := dec.ctx
+	d.Metadata()[obj] = props
+	dec.decodeProperties(m, path, class, props)
+	if name == "HBox" {
+		props["dir"] = "horizontal"
+	} else if name == "VBox" {
+		props["dir"] = "vertical"
+	}
+
+	if m["Objects"] != nil {
+		objPath := joinPath(path, "Objects")
+		children, ok := m["Objects"].([]interface{})
+		if !ok {
+			dec.report(objPath, class, SeverityError, "Objects should be an array")
+		}
+		for i, data := range children {
+			if data == nil {
+				// Nil object?
+				continue
+			}
+			child := dec.decodeValue(data, indexPath(objPath, i))
+			if child != nil {
+				obj.Objects = append(obj.Objects, child)
+			}
+		}
+	}
+	reg := guidefs.RegistryOf(d)
+	layoutType, ok := reg.Layout(name)
+	if !ok {
+		dec.report(joinPath(path, "Layout"), class, SeverityWarning, "undefined layout "+name+", using Stack")
+		layoutType, _ = reg.Layout("Stack")
 	}
+	obj.Layout = layoutType.Create(obj, d)
+	dec.decodeName(m, path, class, props)
 
-	data, ok := info["Theme"]
+	return obj
+}
+
+func (dec *decoder) decodeThemeOverride(m map[string]interface{}, path string) fyne.CanvasObject {
+	const class = "*container.ThemeOverride"
+	d := dec.ctx
+	info := dec.decodeStruct(m, path, class)
+	content := dec.decodeChild(info, "Content", joinPath(path, "Struct"))
+
+	data, ok := info["Theme"].(string)
 	if !ok || data == "" {
 		data = "{}"
 	}
-	fallback := d.Theme()
-	if fallback == nil {
-		fallback = theme.DefaultTheme()
-	}
-	th, err := theme.FromJSONWithFallback(data.(string), fallback)
+	th, err := theme.FromJSONWithFallback(data, guidefs.ThemeOf(d))
 	if err != nil {
-		fyne.LogError("Theme decode error", err)
+		dec.report(joinPath(path, "Struct.Theme"), class, SeverityWarning, "theme decode error: "+err.Error())
 	}
 	obj := container.NewThemeOverride(content, th)
 
 	props := map[string]string{
-		"data": data.(string),
-	}
-	if name, ok := m["Name"]; ok {
-		props["name"] = name.(string)
+		"data": data,
 	}
+	dec.decodeName(m, path, class, props)
 
 	d.Metadata()[obj] = props
-	return obj, nil
+	return obj
 }
 
-func decodeAppTabs(m map[string]interface{}, d Context) (fyne.CanvasObject, error) {
+func (dec *decoder) decodeAppTabs(m map[string]interface{}, path string) fyne.CanvasObject {
+	const class = "*container.AppTabs"
 	obj := &container.AppTabs{}
-	info := m["Struct"].(map[string]interface{})
+	info := dec.decodeStruct(m, path, class)
 
 	items := info["Items"]
 	if items != nil {
-		for _, c := range items.([]interface{}) {
-			item := &container.TabItem{}
-
-			data := c.(map[string]interface{})
-			item.Text = data["Text"].(string)
+		itemsPath := joinPath(path, "Struct.Items")
+		list, ok := items.([]interface{})
+		if !ok {
+			dec.report(itemsPath, class, SeverityError, "Items should be an array")
+		}
+		for i, c := range list {
+			itemPath := indexPath(itemsPath, i)
+			data, ok := c.(map[string]interface{})
+			if !ok {
+				dec.report(itemPath, class, SeverityError, "tab item should be an object")
+				continue
+			}
 
-			if data["Icon"] != nil {
-				res := guidefs.Icons[data["Icon"].(string)]
+			item := &container.TabItem{}
+			if text, ok := data["Text"].(string); ok {
+				item.Text = text
+			}
+			if icon, ok := data["Icon"].(string); ok {
+				res := guidefs.LookupResource(icon, dec.ctx)
 				if res != nil {
 					item.Icon = res
 				}
 			}
-			item.Content, _ = DecodeMap(data["Content"].(map[string]interface{}), d)
+			item.Content = dec.decodeChild(data, "Content", itemPath)
 			obj.Append(item)
 		}
 	}
 
 	props := map[string]string{}
-	if name, ok := m["Name"]; ok {
-		props["name"] = name.(string)
-	}
-	if index, ok := info["SelectedIndex"]; ok {
-		obj.SelectIndex(int(index.(float64)))
+	dec.decodeName(m, path, class, props)
+	if index, ok := info["SelectedIndex"].(float64); ok {
+		obj.SelectIndex(int(index))
 	}
-	if loc, ok := info["TabLocation"]; ok {
-		props["location"] = loc.(string)
+	if loc, ok := info["TabLocation"].(string); ok {
+		props["location"] = loc
 
 		switch loc {
 		case "Bottom":
@@ -246,113 +436,140 @@ func decodeAppTabs(m map[string]interface{}, d Context) (fyne.CanvasObject, erro
 		}
 	}
 
-	d.Metadata()[obj] = props
-	return obj, nil
+	dec.ctx.Metadata()[obj] = props
+	return obj
 }
 
-func decodeClip(m map[string]interface{}, d Context) (fyne.CanvasObject, error) {
+func (dec *decoder) decodeClip(m map[string]interface{}, path string) fyne.CanvasObject {
 	obj := &container.Clip{}
-	info := m["Struct"].(map[string]interface{})
-	if info["Content"] != nil {
-		child, _ := DecodeMap(info["Content"].(map[string]interface{}), d)
-		obj.Content = child
-	}
+	info := dec.decodeStruct(m, path, "*container.Clip")
+	obj.Content = dec.decodeChild(info, "Content", joinPath(path, "Struct"))
 
 	props := map[string]string{}
-	d.Metadata()[obj] = props
-	return obj, nil
+	dec.ctx.Metadata()[obj] = props
+	return obj
 }
 
-func decodeNavigation(m map[string]interface{}, d Context) (fyne.CanvasObject, error) {
+func (dec *decoder) decodeNavigation(m map[string]interface{}, path string) fyne.CanvasObject {
+	const class = "*container.Navigation"
 	obj := &container.Navigation{}
-	info := m["Struct"].(map[string]interface{})
-	if info["Root"] != nil {
-		child, _ := DecodeMap(info["Root"].(map[string]interface{}), d)
-		obj.Root = child
-	}
-	if title, ok := info["Title"]; ok {
-		obj.Title = title.(string)
+	info := dec.decodeStruct(m, path, class)
+	obj.Root = dec.decodeChild(info, "Root", joinPath(path, "Struct"))
+	if title, ok := info["Title"].(string); ok {
+		obj.Title = title
 	}
 
 	props := map[string]string{}
-	if name, ok := m["Name"]; ok {
-		props["name"] = name.(string)
-	}
+	dec.decodeName(m, path, class, props)
 
-	d.Metadata()[obj] = props
-	return obj, nil
+	dec.ctx.Metadata()[obj] = props
+	return obj
 }
 
-func decodeScroll(m map[string]interface{}, d Context) (fyne.CanvasObject, error) {
+func (dec *decoder) decodeScroll(m map[string]interface{}, path string) fyne.CanvasObject {
+	const class = "*container.Scroll"
 	obj := &container.Scroll{}
-	info := m["Struct"].(map[string]interface{})
-	if off, ok := info["Direction"]; ok {
-		obj.Direction = container.ScrollDirection(off.(float64))
-	}
-	if info["Content"] != nil {
-		child, _ := DecodeMap(info["Content"].(map[string]interface{}), d)
-		obj.Content = child
+	info := dec.decodeStruct(m, path, class)
+	if off, ok := info["Direction"].(float64); ok {
+		obj.Direction = container.ScrollDirection(off)
 	}
+	obj.Content = dec.decodeChild(info, "Content", joinPath(path, "Struct"))
 
 	props := map[string]string{}
-	if name, ok := m["Name"]; ok {
-		props["name"] = name.(string)
-	}
+	dec.decodeName(m, path, class, props)
 
-	d.Metadata()[obj] = props
-	return obj, nil
+	dec.ctx.Metadata()[obj] = props
+	return obj
 }
 
-func decodeSplit(m map[string]interface{}, d Context) (fyne.CanvasObject, error) {
+func (dec *decoder) decodeSplit(m map[string]interface{}, path string) fyne.CanvasObject {
+	const class = "*container.Split"
 	obj := &container.Split{}
-	info := m["Struct"].(map[string]interface{})
-	if info["Horizontal"].(bool) {
+	info := dec.decodeStruct(m, path, class)
+	if horiz, ok := info["Horizontal"].(bool); ok && horiz {
 		obj.Horizontal = true
 	}
-	if off, ok := info["Offset"]; ok {
-		obj.Offset = off.(float64)
-	}
-	if info["Leading"] != nil {
-		child, _ := DecodeMap(info["Leading"].(map[string]interface{}), d)
-		obj.Leading = child
-	}
-	if info["Trailing"] != nil {
-		child, _ := DecodeMap(info["Trailing"].(map[string]interface{}), d)
-		obj.Trailing = child
+	if off, ok := info["Offset"].(float64); ok {
+		obj.Offset = off
 	}
+	obj.Leading = dec.decodeChild(info, "Leading", joinPath(path, "Struct"))
+	obj.Trailing = dec.decodeChild(info, "Trailing", joinPath(path, "Struct"))
 
 	props := map[string]string{}
-	if name, ok := m["Name"]; ok {
-		props["name"] = name.(string)
-	}
+	dec.decodeName(m, path, class, props)
 
-	d.Metadata()[obj] = props
-	return obj, nil
+	dec.ctx.Metadata()[obj] = props
+	return obj
 }
 
 // EncodeObject writes a JSON stream for the tree of `CanvasObject` elements provided.
+// The tree is wrapped in a document that records the current `FormatVersion`.
 // If an error occurs it will be returned, otherwise nil.
 func EncodeObject(obj fyne.CanvasObject, d Context, w io.Writer) error {
-	guidefs.InitOnce()
-	tree, _ := EncodeMap(obj, d)
+	return EncodeObjectWithOptions(obj, d, w, EncodeOptions{})
+}
+
+// EncodeObjectWithOptions is like `EncodeObject` but allows the encoding behaviour to be configured.
+func EncodeObjectWithOptions(obj fyne.CanvasObject, d Context, w io.Writer, opts EncodeOptions) error {
+	doc, err := encodeDocument(obj, d, opts)
+	if err != nil {
+		return err
+	}
 
 	e := json.NewEncoder(w)
 	e.SetIndent("", "  ")
-	return e.Encode(tree)
+	return e.Encode(doc)
+}
+
+// encodeDocument returns the document envelope for the tree of `CanvasObject` elements provided.
+func encodeDocument(obj fyne.CanvasObject, d Context, opts EncodeOptions) (interface{}, error) {
+	guidefs.InitOnce()
+	tree, err := encodeMap(obj, d, opts.expandComponent)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &document{Canonical: opts.Canonical, Version: FormatVersion, Object: tree}
+	if !opts.Canonical {
+		return doc, nil
+	}
+	return canonicalTree(doc, d)
 }
 
 // EncodeMap returns a JSON map for the tree of `CanvasObject` elements provided, using additional metadata if required.
 // If an error occurs it will be returned, otherwise nil.
 func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
+	return encodeMap(obj, d, false)
+}
+
+// encodeMap is like `EncodeMap` but can write out the objects of a component root, rather than a reference to it.
+func encodeMap(obj fyne.CanvasObject, d Context, expandComponent bool) (interface{}, error) {
 	guidefs.InitOnce()
+	if obj == nil {
+		return nil, errors.New("cannot encode a nil object")
+	}
 
 	props := d.Metadata()[obj]
+	if raw := props[rawJSONKey]; raw != "" {
+		return json.RawMessage(raw), nil
+	}
+	if props[guidefs.ComponentKey] != "" {
+		if !expandComponent {
+			return encodeComponent(props), nil
+		}
+
+		expanded := make(map[string]string, len(props))
+		for k, v := range props {
+			if k != guidefs.ComponentKey && k != guidefs.ComponentOverridesKey {
+				expanded[k] = v
+			}
+		}
+		props = expanded
+	}
+
 	name := ""
 	actions := map[string]string{}
-	if props == nil {
-		props = make(map[string]string)
-		d.Metadata()[obj] = props
-	} else {
+	if props != nil {
 		name = props["name"]
 
 		for k, v := range props {
@@ -363,23 +580,6 @@ func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
 	}
 
 	switch c := obj.(type) {
-	case *canvas.Image:
-		img := c.Image
-		res := c.Resource
-		go func() { // TODO find a better way to reset this after encoding
-			time.Sleep(time.Millisecond * 100)
-			c.Image = img
-			c.Resource = res
-		}()
-
-		c.Image = nil
-		if c.Resource == nil {
-			return encodeWidget(c, name, actions, props), nil
-		}
-
-		c.Resource = guidefs.WrapResource(c.Resource)
-		wid := encodeWidget(c, name, actions, props)
-		return wid, nil
 	case *widget.Accordion:
 		node := &cntObj{Struct: make(map[string]interface{})}
 		node.Type = "*widget.Accordion"
@@ -389,8 +589,13 @@ func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
 		for i, child := range c.Items {
 			data := map[string]interface{}{
 				"Title": child.Title,
+				"Open":  child.Open,
 			}
-			data["Detail"], _ = EncodeMap(child.Detail, d)
+			detail, err := EncodeMap(child.Detail, d)
+			if err != nil {
+				return nil, err
+			}
+			data["Detail"] = detail
 
 			items[i] = data
 		}
@@ -398,32 +603,6 @@ func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
 		node.Struct["MultiOpen"] = c.MultiOpen
 
 		return &node, nil
-	case *widget.Button:
-		if c.Icon == nil {
-			return encodeWidget(c, name, actions, props), nil
-		}
-
-		ic := c.Icon
-		c.Icon = guidefs.WrapResource(c.Icon)
-		wid := encodeWidget(c, name, actions, props)
-		go func() { // TODO find a better way to reset this after encoding
-			time.Sleep(time.Millisecond * 100)
-			c.Icon = ic
-		}()
-		return wid, nil
-	case *widget.Icon:
-		if c.Resource == nil {
-			return encodeWidget(c, name, actions, props), nil
-		}
-
-		ic := c.Resource
-		c.Resource = guidefs.WrapResource(c.Resource)
-		wid := encodeWidget(c, name, actions, props)
-		go func() { // TODO find a better way to reset this after encoding
-			time.Sleep(time.Millisecond * 100)
-			c.Resource = ic
-		}()
-		return wid, nil
 	case *widget.Toolbar:
 		node := &cntObj{Struct: make(map[string]interface{})}
 		node.Type = "*widget.Toolbar"
@@ -434,7 +613,7 @@ func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
 			data := map[string]interface{}{}
 			switch t := child.(type) {
 			case *widget.ToolbarAction:
-				data["Icon"] = guidefs.WrapResource(t.Icon)
+				data["Icon"] = guidefs.WrapResource(t.Icon, d)
 				data["Type"] = "Action"
 			case *widget.ToolbarSeparator:
 				data["Type"] = "Separator"
@@ -458,9 +637,13 @@ func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
 				"Text": child.Text,
 			}
 			if child.Icon != nil {
-				data["Icon"] = guidefs.WrapResource(child.Icon)
+				data["Icon"] = guidefs.WrapResource(child.Icon, d)
+			}
+			content, err := EncodeMap(child.Content, d)
+			if err != nil {
+				return nil, err
 			}
-			data["Content"], _ = EncodeMap(child.Content, d)
+			data["Content"] = content
 
 			items[i] = data
 		}
@@ -474,7 +657,11 @@ func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
 		node.Type = "*container.Clip"
 		node.Name = name
 
-		node.Struct["Content"], _ = EncodeMap(c.Content, d)
+		content, err := EncodeMap(c.Content, d)
+		if err != nil {
+			return nil, err
+		}
+		node.Struct["Content"] = content
 
 		return &node, nil
 	case *container.Navigation:
@@ -483,7 +670,11 @@ func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
 		node.Struct["Title"] = c.Title
 		node.Name = name
 
-		node.Struct["Root"], _ = EncodeMap(c.Root, d)
+		root, err := EncodeMap(c.Root, d)
+		if err != nil {
+			return nil, err
+		}
+		node.Struct["Root"] = root
 
 		return &node, nil
 	case *container.Scroll:
@@ -492,7 +683,11 @@ func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
 		node.Struct["Direction"] = c.Direction
 		node.Name = name
 
-		node.Struct["Content"], _ = EncodeMap(c.Content, d)
+		content, err := EncodeMap(c.Content, d)
+		if err != nil {
+			return nil, err
+		}
+		node.Struct["Content"] = content
 
 		return &node, nil
 	case *container.ThemeOverride:
@@ -500,7 +695,11 @@ func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
 		node.Type = "*container.ThemeOverride"
 		node.Name = name
 
-		node.Struct["Content"], _ = EncodeMap(c.Content, d)
+		content, err := EncodeMap(c.Content, d)
+		if err != nil {
+			return nil, err
+		}
+		node.Struct["Content"] = content
 		node.Struct["Theme"] = d.Metadata()[c]["data"]
 
 		return &node, nil
@@ -511,15 +710,23 @@ func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
 		node.Struct["Offset"] = c.Offset
 		node.Name = name
 
-		node.Struct["Leading"], _ = EncodeMap(c.Leading, d)
-		node.Struct["Trailing"], _ = EncodeMap(c.Trailing, d)
+		leading, err := EncodeMap(c.Leading, d)
+		if err != nil {
+			return nil, err
+		}
+		node.Struct["Leading"] = leading
+		trailing, err := EncodeMap(c.Trailing, d)
+		if err != nil {
+			return nil, err
+		}
+		node.Struct["Trailing"] = trailing
 
 		return &node, nil
 	case fyne.Widget:
 		if form, ok := c.(*widget.Form); ok {
-			return encodeForm(form, name, props), nil
+			return encodeForm(form, d, name, props)
 		}
-		return encodeWidget(c, name, actions, props), nil
+		return encodeWidget(c, d, name, actions, props), nil
 	case *fyne.Container:
 		var node cont
 		node.Type = "*fyne.Container"
@@ -539,27 +746,40 @@ func EncodeMap(obj fyne.CanvasObject, d Context) (interface{}, error) {
 				node.Layout = "VBox"
 			}
 		}
+		reg := guidefs.RegistryOf(d)
+		if _, known := reg.Layout(node.Layout); !known {
+			if _, ok := reg.Layout(props["layout"]); ok {
+				node.Layout = props["layout"] // a registered layout whose type is named differently
+			}
+		}
 		for _, o := range c.Objects {
-			enc, _ := EncodeMap(o, d)
+			enc, err := EncodeMap(o, d)
+			if err != nil {
+				return nil, err
+			}
 			node.Objects = append(node.Objects, enc)
 		}
-		node.Properties = d.Metadata()[c]
+		node.Properties = props
 		return &node, nil
 	}
 
-	ret := &canvObj{Type: reflect.TypeOf(obj).String(), Name: name, Struct: obj}
+	ret := &canvObj{Type: reflect.TypeOf(obj).String(), Name: name, Struct: encodeStruct(obj, d)}
 	encodeProperties(props, ret)
 	return ret, nil
 }
 
-func encodeForm(obj *widget.Form, name string, meta map[string]string) interface{} {
+func encodeForm(obj *widget.Form, d Context, name string, meta map[string]string) (interface{}, error) {
 	var items []*formItem
 	for _, o := range obj.Items {
+		child, err := EncodeMap(o.Widget, d)
+		if err != nil {
+			return nil, err
+		}
 		items = append(items,
 			&formItem{
 				HintText: o.HintText,
 				Text:     o.Text,
-				Widget:   encodeWidget(o.Widget, "", nil, nil),
+				Widget:   child,
 			})
 	}
 
@@ -574,7 +794,7 @@ func encodeForm(obj *widget.Form, name string, meta map[string]string) interface
 	}
 	node.Properties = meta
 
-	return &node
+	return &node, nil
 }
 
 func encodeProperties(meta map[string]string, w *canvObj) {
@@ -590,8 +810,8 @@ func encodeProperties(meta map[string]string, w *canvObj) {
 	}
 }
 
-func encodeWidget(obj fyne.CanvasObject, name string, actions map[string]string, meta map[string]string) *canvObj {
-	w := &canvObj{Type: guidefs.TypeName(obj), Name: name, Struct: obj}
+func encodeWidget(obj fyne.CanvasObject, d Context, name string, actions map[string]string, meta map[string]string) *canvObj {
+	w := &canvObj{Type: guidefs.RegistryOf(d).TypeName(obj), Name: name, Struct: encodeStruct(obj, d)}
 
 	if len(actions) > 0 {
 		w.Actions = actions
@@ -600,31 +820,27 @@ func encodeWidget(obj fyne.CanvasObject, name string, actions map[string]string,
 	return w
 }
 
-func decodeAccordionItem(m map[string]interface{}, d Context) *widget.AccordionItem {
+func (dec *decoder) decodeAccordionItem(m map[string]interface{}, path string) *widget.AccordionItem {
 	f := &widget.AccordionItem{}
-	if str, ok := m["Title"]; ok {
-		f.Title = str.(string)
+	if str, ok := m["Title"].(string); ok {
+		f.Title = str
 	}
-	if on, ok := m["Open"]; ok {
-		f.Open = on.(bool)
-	}
-	if wid, ok := m["Detail"]; ok {
-		f.Detail = decodeWidget(wid.(map[string]interface{}), d)
+	if on, ok := m["Open"].(bool); ok {
+		f.Open = on
 	}
+	f.Detail = dec.decodeChild(m, "Detail", path)
 	return f
 }
 
-func decodeFormItem(m map[string]interface{}, d Context) *widget.FormItem {
+func (dec *decoder) decodeFormItem(m map[string]interface{}, path string) *widget.FormItem {
 	f := &widget.FormItem{}
-	if str, ok := m["HintText"]; ok {
-		f.HintText = str.(string)
-	}
-	if str, ok := m["Text"]; ok {
-		f.Text = str.(string)
+	if str, ok := m["HintText"].(string); ok {
+		f.HintText = str
 	}
-	if wid, ok := m["Widget"]; ok {
-		f.Widget = decodeWidget(wid.(map[string]interface{}), d)
+	if str, ok := m["Text"].(string); ok {
+		f.Text = str
 	}
+	f.Widget = dec.decodeChild(m, "Widget", path)
 	return f
 }
 
@@ -640,9 +856,14 @@ func decodeFromMap(m map[string]interface{}, in interface{}) {
 		case reflect.Ptr:
 			continue
 		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			val.SetUint(uint64(reflect.ValueOf(v).Float()))
+			if f, ok := v.(float64); ok {
+				val.SetUint(uint64(f))
+			}
 		default:
-			val.Set(reflect.ValueOf(v))
+			data := reflect.ValueOf(v)
+			if data.IsValid() && data.Type().AssignableTo(val.Type()) {
+				val.Set(data)
+			}
 		}
 	}
 }
@@ -658,20 +879,20 @@ func decodeTextStyle(m map[string]interface{}) (s fyne.TextStyle) {
 		s.Monospace = true
 	}
 
-	if m["TabWidth"] != 0 {
-		s.TabWidth = int(m["TabWidth"].(float64))
+	if width, ok := m["TabWidth"].(float64); ok {
+		s.TabWidth = int(width)
 	}
 	return
 }
 
 func decodePosition(m map[string]interface{}) fyne.Position {
-	x := m["X"].(float64)
-	y := m["Y"].(float64)
+	x, _ := m["X"].(float64)
+	y, _ := m["Y"].(float64)
 
 	return fyne.NewPos(float32(x), float32(y))
 }
 
-func decodeToolbarItem(m map[string]interface{}) widget.ToolbarItem {
+func (dec *decoder) decodeToolbarItem(m map[string]interface{}) widget.ToolbarItem {
 	if v, ok := m["Type"]; ok {
 		switch v {
 		case "Separator":
@@ -681,16 +902,19 @@ func decodeToolbarItem(m map[string]interface{}) widget.ToolbarItem {
 		}
 	}
 
-	return widget.NewToolbarAction(guidefs.Icons[m["Icon"].(string)], nil)
+	icon, _ := m["Icon"].(string)
+	return widget.NewToolbarAction(guidefs.LookupResource(icon, dec.ctx), nil)
 }
 
 func decodeRichTextStyle(m map[string]interface{}) (s widget.RichTextStyle) {
 	for k, v := range m {
 		switch k {
 		case "TextStyle":
-			s.TextStyle = decodeTextStyle(v.(map[string]interface{}))
+			if style, ok := v.(map[string]interface{}); ok {
+				s.TextStyle = decodeTextStyle(style)
+			}
 		case "Inline":
-			s.Inline = v.(bool)
+			s.Inline = v == true
 			// TODO more!
 		}
 	}
@@ -698,149 +922,246 @@ func decodeRichTextStyle(m map[string]interface{}) (s widget.RichTextStyle) {
 	return
 }
 
-func decodeFields(e reflect.Value, in map[string]interface{}, d Context) error {
-	for k, v := range in {
+func (dec *decoder) decodeFields(e reflect.Value, in map[string]interface{}, path, class string) {
+	keys := make([]string, 0, len(in))
+	for k := range in {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // report issues in a stable order
+
+	for _, k := range keys {
+		fieldPath := joinPath(path, k)
 		f := e.FieldByName(k)
 
 		if !f.IsValid() {
-			fyne.LogError("Field "+k+" is not valid", nil)
+			dec.report(fieldPath, class, SeverityWarning, "field "+k+" is not valid")
 			continue
 		}
 
-		typeName := f.Type().String()
-		switch typeName {
-		case "fyne.TextAlign", "fyne.TextTruncation", "fyne.TextWrap", "widget.ButtonAlign", "widget.ButtonImportance",
-			"widget.ButtonIconPlacement", "widget.Importance", "widget.Orientation", "widget.ScrollDirection", "fyne.ScrollDirection",
-			"canvas.ImageFill", "canvas.ImageScale":
-			f.SetInt(int64(reflect.ValueOf(v).Float()))
-		case "fyne.TextStyle":
-			f.Set(reflect.ValueOf(decodeTextStyle(reflect.ValueOf(v).Interface().(map[string]interface{}))))
-		case "widget.RichTextStyle":
-			f.Set(reflect.ValueOf(decodeRichTextStyle(reflect.ValueOf(v).Interface().(map[string]interface{}))))
-		case "fyne.Position":
-			f.Set(reflect.ValueOf(decodePosition(reflect.ValueOf(v).Interface().(map[string]interface{}))))
-		case "fyne.Resource":
-			res := guidefs.Icons[reflect.ValueOf(v).String()]
-			if res != nil {
-				f.Set(reflect.ValueOf(res))
-			}
-		case "fyne.ThemeSizeName":
-			if v != nil {
-				f.Set(reflect.ValueOf(fyne.ThemeSizeName(v.(string))))
-			}
-		case "[]*widget.AccordionItem":
-			var items []*widget.AccordionItem
-			for _, item := range reflect.ValueOf(v).Interface().([]interface{}) {
-				items = append(items, decodeAccordionItem(item.(map[string]interface{}), d))
-			}
-			f.Set(reflect.ValueOf(items))
-		case "[]*widget.FormItem":
-			var items []*widget.FormItem
-			for _, item := range reflect.ValueOf(v).Interface().([]interface{}) {
-				items = append(items, decodeFormItem(item.(map[string]interface{}), d))
-			}
-			f.Set(reflect.ValueOf(items))
-		case "[]widget.ToolbarItem":
-			var items []widget.ToolbarItem
-			for _, item := range reflect.ValueOf(v).Interface().([]interface{}) {
-				items = append(items, decodeToolbarItem(item.(map[string]interface{})))
-			}
-			f.Set(reflect.ValueOf(items))
-		case "[]widget.RichTextSegment":
-			var items []widget.RichTextSegment
-			for _, item := range reflect.ValueOf(v).Interface().([]interface{}) {
-				obj := &widget.TextSegment{}
-				_ = decodeFields(reflect.ValueOf(obj).Elem(), item.(map[string]interface{}), d)
-				items = append(items, obj)
-			}
-			f.Set(reflect.ValueOf(items))
-		case "fyne.CanvasObject":
-			return errors.New("unsupported object type in field: " + k)
-		case "*url.URL":
-			u := &url.URL{}
-			decodeFromMap(reflect.ValueOf(v).Interface().(map[string]interface{}), u)
-			f.Set(reflect.ValueOf(u))
-		case "[]string":
-			anySlice := reflect.ValueOf(v).Interface().([]interface{})
-			strings := make([]string, len(anySlice))
-			for i, a := range anySlice {
-				strings[i] = a.(string)
-			}
-			f.Set(reflect.ValueOf(strings))
-		case "time.Time", "*time.Time":
-			s := reflect.ValueOf(v).String()
-
-			t, err := time.Parse(time.RFC3339, s)
-			switch {
-			case err != nil:
-				fyne.LogError("Failed parse time "+s, err)
-			case typeName == "*time.Time":
-				f.Set(reflect.ValueOf(&t))
-			default:
-				f.Set(reflect.ValueOf(t))
-			}
-		case "color.Color":
-			var c color.Color
-			val := reflect.ValueOf(v)
-			if v == nil {
-				continue
+		dec.decodeField(f, in[k], fieldPath, class)
+	}
+}
+
+func (dec *decoder) decodeField(f reflect.Value, v interface{}, path, class string) {
+	defer func() {
+		// a final safety net, the cases below should check their data
+		if r := recover(); r != nil {
+			dec.report(path, class, SeverityWarning, fmt.Sprint("invalid value: ", r))
+		}
+	}()
+
+	invalid := func(expected string) {
+		dec.report(path, class, SeverityWarning, fmt.Sprintf("expected %s but found %T", expected, v))
+	}
+
+	typeName := f.Type().String()
+	switch typeName {
+	case "fyne.TextAlign", "fyne.TextTruncation", "fyne.TextWrap", "widget.ButtonAlign", "widget.ButtonImportance",
+		"widget.ButtonIconPlacement", "widget.Importance", "widget.Orientation", "widget.ScrollDirection", "fyne.ScrollDirection",
+		"canvas.ImageFill", "canvas.ImageScale":
+		num, ok := v.(float64)
+		if !ok {
+			invalid("number")
+			return
+		}
+		f.SetInt(int64(num))
+	case "fyne.TextStyle":
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			invalid("object")
+			return
+		}
+		f.Set(reflect.ValueOf(decodeTextStyle(data)))
+	case "widget.RichTextStyle":
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			invalid("object")
+			return
+		}
+		f.Set(reflect.ValueOf(decodeRichTextStyle(data)))
+	case "fyne.Position":
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			invalid("object")
+			return
+		}
+		f.Set(reflect.ValueOf(decodePosition(data)))
+	case "fyne.Resource":
+		name, _ := v.(string)
+		res := guidefs.LookupResource(name, dec.ctx)
+		if res != nil {
+			f.Set(reflect.ValueOf(res))
+		}
+	case "fyne.ThemeSizeName":
+		if v == nil {
+			return
+		}
+		name, ok := v.(string)
+		if !ok {
+			invalid("string")
+			return
+		}
+		f.Set(reflect.ValueOf(fyne.ThemeSizeName(name)))
+	case "[]*widget.AccordionItem":
+		var items []*widget.AccordionItem
+		for i, item := range dec.decodeObjectList(v, path, class) {
+			items = append(items, dec.decodeAccordionItem(item, indexPath(path, i)))
+		}
+		f.Set(reflect.ValueOf(items))
+	case "[]*widget.FormItem":
+		var items []*widget.FormItem
+		for i, item := range dec.decodeObjectList(v, path, class) {
+			items = append(items, dec.decodeFormItem(item, indexPath(path, i)))
+		}
+		f.Set(reflect.ValueOf(items))
+	case "[]widget.ToolbarItem":
+		var items []widget.ToolbarItem
+		for _, item := range dec.decodeObjectList(v, path, class) {
+			items = append(items, dec.decodeToolbarItem(item))
+		}
+		f.Set(reflect.ValueOf(items))
+	case "[]widget.RichTextSegment":
+		var items []widget.RichTextSegment
+		for i, item := range dec.decodeObjectList(v, path, class) {
+			obj := &widget.TextSegment{}
+			dec.decodeFields(reflect.ValueOf(obj).Elem(), item, indexPath(path, i), class)
+			items = append(items, obj)
+		}
+		f.Set(reflect.ValueOf(items))
+	case "fyne.CanvasObject":
+		if v == nil {
+			return
+		}
+		if obj := dec.decodeValue(v, path); obj != nil {
+			f.Set(reflect.ValueOf(&obj).Elem())
+		}
+	case "*url.URL":
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			invalid("object")
+			return
+		}
+		u := &url.URL{}
+		decodeFromMap(data, u)
+		f.Set(reflect.ValueOf(u))
+	case "[]string":
+		anySlice, ok := v.([]interface{})
+		if !ok {
+			invalid("array")
+			return
+		}
+		strings := make([]string, len(anySlice))
+		for i, a := range anySlice {
+			strings[i], _ = a.(string)
+		}
+		f.Set(reflect.ValueOf(strings))
+	case "time.Time", "*time.Time":
+		s, _ := v.(string)
+
+		t, err := time.Parse(time.RFC3339, s)
+		switch {
+		case err != nil:
+			dec.report(path, class, SeverityWarning, "failed to parse time "+s)
+		case typeName == "*time.Time":
+			f.Set(reflect.ValueOf(&t))
+		default:
+			f.Set(reflect.ValueOf(t))
+		}
+	case "color.Color":
+		if v == nil {
+			return
+		}
+
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			invalid("object")
+			return
+		}
+		var c color.Color
+		if _, isGray := data["Y"]; isGray {
+			c = &color.Gray16{}
+		} else {
+			c = &color.NRGBA{}
+		}
+		decodeFromMap(data, c)
+		f.Set(reflect.ValueOf(c))
+	default:
+		if strings.Index(typeName, "int") == 0 || strings.Index(typeName, "uint") == 0 || typeName == "float32" {
+			num, ok := v.(float64)
+			if !ok {
+				invalid("number")
+				return
 			}
 
-			data := val.Interface().(map[string]interface{})
-			if _, isGray := data["Y"]; isGray {
-				c = &color.Gray16{}
-			} else {
-				c = &color.NRGBA{}
+			switch f.Kind() {
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				f.SetUint(uint64(num))
+			case reflect.Float32, reflect.Float64:
+				f.SetFloat(num)
+			default:
+				f.SetInt(int64(num))
 			}
-			decodeFromMap(val.Interface().(map[string]interface{}), c)
-			f.Set(reflect.ValueOf(c))
-		default:
-			if strings.Index(typeName, "int") == 0 {
-				f.SetInt(int64(reflect.ValueOf(v).Float()))
-			} else if strings.Index(typeName, "uint") == 0 {
-				f.SetUint(uint64(reflect.ValueOf(v).Float()))
-			} else if typeName == "float32" {
-				f.SetFloat(reflect.ValueOf(v).Float())
-			} else if v != nil {
-				func() {
-					defer func() {
-						if r := recover(); r != nil {
-							log.Println("Panicked decoding", k, "of", e.Type().String(), ":", r)
-						}
-					}()
-					f.Set(reflect.ValueOf(v))
-				}()
+		} else if v != nil {
+			data := reflect.ValueOf(v)
+			if !data.Type().AssignableTo(f.Type()) {
+				invalid(typeName)
+				return
 			}
+			f.Set(data)
 		}
 	}
+}
+
+// decodeObjectList returns the JSON objects in an array value, reporting any other data found.
+func (dec *decoder) decodeObjectList(v interface{}, path, class string) []map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		dec.report(path, class, SeverityWarning, fmt.Sprintf("expected array but found %T", v))
+		return nil
+	}
 
-	return nil
+	items := make([]map[string]interface{}, 0, len(list))
+	for i, item := range list {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			dec.report(indexPath(path, i), class, SeverityWarning, fmt.Sprintf("expected object but found %T", item))
+			continue
+		}
+		items = append(items, data)
+	}
+	return items
 }
 
-func decodeWidget(m map[string]interface{}, d Context) fyne.CanvasObject {
+func (dec *decoder) decodeWidget(m map[string]interface{}, path string) fyne.CanvasObject {
 	class, ok := m["Type"].(string)
 	if !ok {
-		log.Println("Failed to detect type of object", m)
-		return nil
+		return dec.placeholder(m, path, "", "failed to detect type of object")
 	}
-	def := guidefs.Lookup(class)
+	def := guidefs.RegistryOf(dec.ctx).Lookup(class)
 	if def == nil {
-		log.Println("Failed to find object definition for", class)
-		return nil
+		return dec.placeholder(m, path, class, "failed to find object definition")
 	}
-	obj := def.Create(d)
+	obj := def.Create(dec.ctx)
 	e := reflect.ValueOf(obj).Elem()
 
 	data, ok := m["Struct"]
 	if !ok {
-		fyne.LogError("Struct was not found in JSON data", nil)
+		dec.report(path, class, SeverityWarning, "Struct was not found")
+		return obj
+	}
+	fields, ok := data.(map[string]interface{})
+	if !ok {
+		dec.report(joinPath(path, "Struct"), class, SeverityError, "Struct should be an object")
 		return obj
 	}
 
-	err := decodeFields(e, data.(map[string]interface{}), d)
-	if err != nil {
-		fyne.LogError("Failed to handle type "+class, err)
+	if dec.canonical && !canonicalKeepFields[class] {
+		zeroMissingFields(e, fields)
 	}
+	dec.decodeFields(e, fields, joinPath(path, "Struct"), class)
 
 	if form, ok := obj.(*widget.Form); ok {
 		if props, ok := m["Properties"].(map[string]any); ok {
@@ -853,3 +1174,42 @@ func decodeWidget(m map[string]interface{}, d Context) fyne.CanvasObject {
 
 	return obj
 }
+
+// zeroMissingFields sets the encoded fields of an object that are not in the data to their zero value,
+// as a canonical document leaves them out.
+func zeroMissingFields(e reflect.Value, in map[string]interface{}) {
+	for _, f := range encodedFields(e.Type()) {
+		if _, ok := in[f.name]; ok {
+			continue
+		}
+		if field, err := e.FieldByIndexErr(f.index); err == nil && field.CanSet() {
+			field.Set(reflect.Zero(field.Type()))
+		}
+	}
+}
+
+// placeholder reports an object that could not be decoded.
+// In lenient mode a label is returned in its place, holding the original JSON in its metadata,
+// otherwise the object is dropped and nil is returned.
+func (dec *decoder) placeholder(data interface{}, path, class, msg string) fyne.CanvasObject {
+	if !dec.opts.Lenient {
+		dec.report(path, class, SeverityError, msg)
+		return nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		dec.report(path, class, SeverityError, msg)
+		return nil
+	}
+	dec.report(path, class, SeverityWarning, msg+", kept as placeholder")
+
+	text := "Unknown type " + class
+	if class == "" {
+		text = "Unknown object"
+	}
+	l := widget.NewLabel(text)
+	l.Importance = widget.WarningImportance
+	dec.ctx.Metadata()[l] = map[string]string{rawJSONKey: string(raw)}
+	return l
+}
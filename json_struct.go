@@ -0,0 +1,136 @@
+package refyne
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+)
+
+var resourceType = reflect.TypeOf((*fyne.Resource)(nil)).Elem()
+
+// structValue encodes the exported fields of an object in the same way as encoding/json,
+// except that resources are written by name, child objects are encoded as subtrees and image data is left out.
+// It only reads from the object so that encoding never modifies the live object tree.
+type structValue struct {
+	v   reflect.Value
+	ctx Context
+}
+
+func encodeStruct(obj interface{}, d Context) *structValue {
+	return &structValue{v: reflect.ValueOf(obj), ctx: d}
+}
+
+func (s *structValue) MarshalJSON() ([]byte, error) {
+	v := s.v
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return []byte("null"), nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return json.Marshal(v.Interface())
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	written := 0
+	for _, f := range encodedFields(v.Type()) {
+		field, err := v.FieldByIndexErr(f.index)
+		if err != nil { // a nil embedded pointer
+			continue
+		}
+		if f.omitEmpty && field.IsZero() {
+			continue
+		}
+
+		data, err := s.encodeField(field)
+		if err != nil {
+			return nil, err
+		}
+
+		if written > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(data)
+		written++
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (s *structValue) encodeField(field reflect.Value) ([]byte, error) {
+	switch {
+	case field.Type() == canvasObjectType:
+		if field.IsNil() {
+			return []byte("null"), nil
+		}
+		tree, err := EncodeMap(field.Interface().(fyne.CanvasObject), s.ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(tree)
+	case field.Type().String() == "image.Image":
+		return []byte("null"), nil // pixel data is not stored, the File or Resource is used instead
+	case field.Type() == resourceType:
+		if field.IsNil() {
+			return []byte("null"), nil
+		}
+		return json.Marshal(guidefs.WrapResource(field.Interface().(fyne.Resource), s.ctx))
+	}
+
+	return json.Marshal(field.Interface())
+}
+
+// encodedField describes an exported field that is written when a struct is encoded.
+type encodedField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// encodedFields returns the fields of a struct type that are written by `structValue`, in the order of encoding/json.
+// Embedded structs are not listed as their promoted fields follow.
+func encodedFields(t reflect.Type) []encodedField {
+	var fields []encodedField
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous {
+			t := f.Type
+			if t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Struct {
+				continue // the promoted fields follow
+			}
+		}
+
+		field := encodedField{name: f.Name, index: f.Index}
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" && len(parts) == 1 {
+				continue
+			}
+			if parts[0] != "" {
+				field.name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				if opt == "omitempty" {
+					field.omitEmpty = true
+				}
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
@@ -2,13 +2,17 @@ package refyne
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"image/color"
 	"strings"
 	"testing"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	_ "fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/fyne-io/refyne/internal/guidefs"
@@ -39,7 +43,11 @@ const labelJSON = `{
 }`
 
 func labelJSONWith(indent string) string {
-	in := fmt.Sprintf(labelJSON, "")
+	return indentJSON(fmt.Sprintf(labelJSON, ""), indent)
+}
+
+// indentJSON adds the indent to all but the first line of the input
+func indentJSON(in, indent string) string {
 	out := ""
 
 	rows := strings.Split(in, "\n")
@@ -58,6 +66,11 @@ func labelJSONWith(indent string) string {
 	return out
 }
 
+// documentJSON wraps the object JSON in a document envelope, as written by EncodeObject
+func documentJSON(obj string) string {
+	return fmt.Sprintf("{\n  \"Version\": %d,\n  \"Object\": %s\n}\n", FormatVersion, indentJSON(strings.TrimSpace(obj), "  "))
+}
+
 var splitJSON = `{
   "Type": "*container.Split",
   "Name": "mySplit",
@@ -119,7 +132,7 @@ func TestEncodeObject(t *testing.T) {
 	var buf bytes.Buffer
 	err := EncodeObject(l, &context{meta: meta}, &buf)
 	assert.Nil(t, err)
-	assert.Equal(t, fmt.Sprintf(labelJSON, "\n  \"Name\": \"myLabel\",")+"\n", buf.String())
+	assert.Equal(t, documentJSON(fmt.Sprintf(labelJSON, "\n  \"Name\": \"myLabel\",")), buf.String())
 }
 
 func TestEncodeSplit(t *testing.T) {
@@ -135,5 +148,269 @@ func TestEncodeSplit(t *testing.T) {
 	var buf bytes.Buffer
 	err := EncodeObject(s, &context{meta: meta}, &buf)
 	assert.Nil(t, err)
-	assert.Equal(t, splitJSON, buf.String())
+	assert.Equal(t, documentJSON(splitJSON), buf.String())
+}
+
+func TestEncodeChildErrors(t *testing.T) {
+	s := container.NewHSplit(widget.NewLabel("Hi"), nil)
+	var buf bytes.Buffer
+	assert.Error(t, EncodeObject(container.NewVBox(s), DefaultContext(), &buf))
+	assert.Zero(t, buf.Len())
+
+	acc := widget.NewAccordion(widget.NewAccordionItem("Item", nil))
+	_, err := EncodeMap(container.NewAppTabs(container.NewTabItem("Tab", acc)), DefaultContext())
+	assert.Error(t, err)
+}
+
+func TestEncodeResources(t *testing.T) {
+	b := widget.NewButtonWithIcon("Tap", theme.HomeIcon(), nil)
+	i := widget.NewIcon(theme.InfoIcon())
+	img := canvas.NewImageFromResource(theme.FyneLogo())
+	c := container.NewVBox(b, i, img)
+
+	meta := map[fyne.CanvasObject]map[string]string{}
+	var buf bytes.Buffer
+	err := EncodeObject(c, &context{meta: meta}, &buf)
+	assert.Nil(t, err)
+
+	assert.Equal(t, theme.HomeIcon(), b.Icon)
+	assert.Equal(t, theme.InfoIcon(), i.Resource)
+	assert.Equal(t, theme.FyneLogo(), img.Resource)
+	assert.Empty(t, meta)
+
+	var doc struct {
+		Object struct {
+			Objects []struct{ Struct map[string]interface{} }
+		}
+	}
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Object.Objects, 3)
+	assert.Equal(t, "HomeIcon", doc.Object.Objects[0].Struct["Icon"])
+	assert.Equal(t, "InfoIcon", doc.Object.Objects[1].Struct["Resource"])
+	assert.Nil(t, doc.Object.Objects[2].Struct["Image"])
+}
+
+func TestEncodeBindings(t *testing.T) {
+	ctx := DefaultContext()
+	e := widget.NewEntry()
+	ctx.Metadata()[e] = map[string]string{"name": "user", "Data": "username"}
+	s := widget.NewSlider(0, 10)
+	ctx.Metadata()[s] = map[string]string{"Data": "volume"}
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeObject(container.NewVBox(e, s), ctx, &buf))
+
+	ctx2 := DefaultContext()
+	obj, err := DecodeObject(&buf, ctx2)
+	require.NoError(t, err)
+	objs := obj.(*fyne.Container).Objects
+	assert.Equal(t, "username", guidefs.Binding(objs[0], ctx2))
+	assert.Equal(t, "volume", guidefs.Binding(objs[1], ctx2))
+}
+
+func TestEncodeCollections(t *testing.T) {
+	ctx := DefaultContext()
+	table := CreateNew("*widget.Table", ctx)
+	ctx.Metadata()[table] = map[string]string{"rows": "4", "columns": "2", "headers": "Name, Age"}
+	tree := CreateNew("*widget.Tree", ctx)
+	ctx.Metadata()[tree] = map[string]string{"items": "Fruit\n\tApple\n\tPear"}
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeObject(container.NewVBox(table, tree), ctx, &buf))
+
+	ctx2 := DefaultContext()
+	obj, err := DecodeObject(&buf, ctx2)
+	require.NoError(t, err)
+	objs := obj.(*fyne.Container).Objects
+
+	decodedTable := objs[0].(*widget.Table)
+	assert.Equal(t, "Name, Age", ctx2.Metadata()[decodedTable]["headers"])
+	rows, cols := decodedTable.Length()
+	assert.Equal(t, 4, rows)
+	assert.Equal(t, 2, cols)
+	cell := decodedTable.CreateCell()
+	decodedTable.UpdateCell(widget.TableCellID{Row: 1, Col: 0}, cell)
+	assert.Equal(t, "Cell 2, 1", cell.(*widget.Label).Text)
+
+	decodedTree := objs[1].(*widget.Tree)
+	assert.Equal(t, []string{"Fruit"}, decodedTree.ChildUIDs(""))
+	assert.Equal(t, []string{"Apple", "Pear"}, decodedTree.ChildUIDs("Fruit"))
+	assert.False(t, decodedTree.IsBranch("Pear"))
+}
+
+func TestEncodeContainerWidgets(t *testing.T) {
+	ctx := DefaultContext()
+	title := widget.NewLabel("Details")
+	detail := container.NewVBox(title)
+	ctx.Metadata()[title] = map[string]string{"name": "detailTitle"}
+	ctx.Metadata()[detail] = map[string]string{"name": "details", "layout": "VBox"}
+	acc := widget.NewAccordion(widget.NewAccordionItem("More", detail))
+	acc.Items[0].Open = true
+
+	content := container.NewHBox(widget.NewLabel("Inside"))
+	ctx.Metadata()[content] = map[string]string{"name": "cardContent", "layout": "HBox"}
+	card := widget.NewCard("Title", "Subtitle", content)
+
+	user := widget.NewEntry()
+	ctx.Metadata()[user] = map[string]string{"name": "user", "OnChanged": "g.userChanged"}
+	form := widget.NewForm(widget.NewFormItem("User", user))
+	ctx.Metadata()[form] = map[string]string{"hideButtons": "true"}
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeObject(container.NewVBox(acc, card, form), ctx, &buf))
+
+	ctx2 := DefaultContext()
+	obj, err := DecodeObject(&buf, ctx2)
+	require.NoError(t, err)
+	objs := obj.(*fyne.Container).Objects
+
+	decodedAcc := objs[0].(*widget.Accordion)
+	require.Len(t, decodedAcc.Items, 1)
+	assert.True(t, decodedAcc.Items[0].Open)
+	decodedDetail := decodedAcc.Items[0].Detail.(*fyne.Container)
+	assert.Equal(t, "details", ctx2.Metadata()[decodedDetail]["name"])
+	assert.Equal(t, "detailTitle", ctx2.Metadata()[decodedDetail.Objects[0]]["name"])
+
+	decodedContent := objs[1].(*widget.Card).Content.(*fyne.Container)
+	assert.Equal(t, "cardContent", ctx2.Metadata()[decodedContent]["name"])
+	assert.Equal(t, "HBox", ctx2.Metadata()[decodedContent]["layout"])
+
+	decodedUser := objs[2].(*widget.Form).Items[0].Widget
+	assert.Equal(t, "user", ctx2.Metadata()[decodedUser]["name"])
+	assert.Equal(t, "g.userChanged", ctx2.Metadata()[decodedUser]["OnChanged"])
+
+	code := &bytes.Buffer{}
+	require.NoError(t, ExportGo(obj, ctx2, "main", code))
+	assert.Contains(t, code.String(), `&widget.AccordionItem{Title: "More", Detail: g.details, Open: true}`)
+	assert.Contains(t, code.String(), `g.details = container.NewVBox(`)
+	assert.Contains(t, code.String(), `widget.NewCard("Title", "Subtitle",
+			g.cardContent)`)
+	assert.Contains(t, code.String(), `widget.NewFormItem("User",
+			g.user)`)
+	assert.Contains(t, code.String(), `g.user.OnChanged = g.userChanged`)
+	assert.Contains(t, code.String(), "\tdetailTitle *widget.Label\n")
+}
+
+func TestEncodeCustomResources(t *testing.T) {
+	logo := fyne.NewStaticResource("logo.svg", []byte("<svg/>"))
+	ctx := DefaultContext()
+	ResourcesOf(ctx)["logo"] = Resource{Resource: logo, Embed: "assets/logo.svg"}
+	b := widget.NewButtonWithIcon("Tap", logo, nil)
+	bar := widget.NewToolbar(widget.NewToolbarAction(logo, nil))
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeObject(container.NewVBox(b, bar), ctx, &buf))
+	assert.Contains(t, buf.String(), `"Icon": "logo"`)
+	assert.NotContains(t, buf.String(), "BrokenImageIcon")
+
+	ctx2 := DefaultContext()
+	ResourcesOf(ctx2)["logo"] = Resource{Resource: logo, Embed: "assets/logo.svg"}
+	obj, err := DecodeObject(&buf, ctx2)
+	require.NoError(t, err)
+	objs := obj.(*fyne.Container).Objects
+	assert.Equal(t, logo, objs[0].(*widget.Button).Icon)
+	assert.Equal(t, logo, objs[1].(*widget.Toolbar).Items[0].(*widget.ToolbarAction).Icon)
+}
+
+func TestEncodeThemeReferences(t *testing.T) {
+	ctx := DefaultContext()
+	rect := canvas.NewRectangle(color.Black)
+	ctx.Metadata()[rect] = map[string]string{"themeFillColor": "primary", "themeCornerRadius": "padding"}
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeObject(rect, ctx, &buf))
+
+	ctx2 := DefaultContext()
+	obj, err := DecodeObject(&buf, ctx2)
+	require.NoError(t, err)
+	decoded := obj.(*canvas.Rectangle)
+	assert.Equal(t, "primary", ctx2.Metadata()[decoded]["themeFillColor"])
+	assert.Equal(t, theme.DefaultTheme().Color(theme.ColorNamePrimary, theme.VariantDark), decoded.FillColor)
+	assert.Equal(t, theme.DefaultTheme().Size(theme.SizeNamePadding), decoded.CornerRadius)
+}
+
+func TestDecodeObjectErrors(t *testing.T) {
+	buf := bytes.NewReader([]byte(`{
+  "Type": "*fyne.Container",
+  "Layout": "VBox",
+  "Objects": [
+    ` + labelJSONWith("    ") + `,
+    {
+      "Type": "*widget.Unknown",
+      "Struct": {}
+    },
+    {
+      "Type": "*container.AppTabs",
+      "Struct": {
+        "Items": [
+          {"Text": "Tab 1", "Content": ` + labelJSONWith("          ") + `},
+          {"Text": "Tab 2", "Content": "oops"}
+        ]
+      }
+    },
+    {
+      "Type": "*widget.Label",
+      "Struct": {"Text": 5, "Nonsense": true}
+    }
+  ]
+}`))
+	obj, err := DecodeObject(buf, DefaultContext())
+	require.NotNil(t, obj)
+	assert.Len(t, obj.(*fyne.Container).Objects, 3)
+
+	var decErr *DecodeError
+	require.ErrorAs(t, err, &decErr)
+	assert.True(t, decErr.HasErrors())
+	require.Len(t, decErr.Issues, 4)
+
+	assert.Equal(t, "Objects[1]", decErr.Issues[0].Path)
+	assert.Equal(t, "*widget.Unknown", decErr.Issues[0].Type)
+	assert.Equal(t, SeverityError, decErr.Issues[0].Severity)
+	assert.Equal(t, "Objects[2].Struct.Items[1].Content", decErr.Issues[1].Path)
+	assert.Equal(t, SeverityError, decErr.Issues[1].Severity)
+	assert.Equal(t, "Objects[3].Struct.Nonsense", decErr.Issues[2].Path)
+	assert.Equal(t, "*widget.Label", decErr.Issues[2].Type)
+	assert.Equal(t, SeverityWarning, decErr.Issues[2].Severity)
+	assert.Equal(t, "Objects[3].Struct.Text", decErr.Issues[3].Path)
+	assert.Equal(t, SeverityWarning, decErr.Issues[3].Severity)
+}
+
+func TestDecodeObjectLenient(t *testing.T) {
+	const unknown = `{"Type":"*other.Widget","Name":"fancy","Struct":{"Colour":"red","Size":3}}`
+	in := `{
+  "Type": "*fyne.Container",
+  "Layout": "VBox",
+  "Objects": [
+    ` + labelJSONWith("    ") + `,
+    ` + unknown + `
+  ]
+}`
+	ctx := DefaultContext()
+	obj, err := DecodeObjectWithOptions(strings.NewReader(in), ctx, DecodeOptions{Lenient: true})
+	var decErr *DecodeError
+	require.ErrorAs(t, err, &decErr)
+	assert.False(t, decErr.HasErrors())
+	require.Len(t, decErr.Issues, 1)
+	assert.Equal(t, "Objects[1]", decErr.Issues[0].Path)
+
+	c := obj.(*fyne.Container)
+	require.Len(t, c.Objects, 2)
+	placeholder, ok := c.Objects[1].(*widget.Label)
+	require.True(t, ok)
+	assert.Equal(t, "Unknown type *other.Widget", placeholder.Text)
+
+	out, err := EncodeMap(obj, ctx)
+	require.NoError(t, err)
+	data, err := json.Marshal(out)
+	require.NoError(t, err)
+
+	var tree map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &tree))
+	var expected map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(unknown), &expected))
+	assert.Equal(t, expected, tree["Objects"].([]interface{})[1])
+
+	_, err = DecodeObject(strings.NewReader(in), DefaultContext())
+	require.ErrorAs(t, err, &decErr)
+	assert.True(t, decErr.HasErrors())
 }
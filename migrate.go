@@ -0,0 +1,126 @@
+package refyne
+
+import (
+	"fmt"
+)
+
+// FormatVersion is the version of the JSON document format written by `EncodeObject`.
+// Documents without a version, from before the format was versioned, are treated as version 0.
+const FormatVersion = 1
+
+// Migration upgrades the object tree of a decoded JSON document by one format version.
+// It may modify the map in place or return a new one.
+type Migration func(root map[string]interface{}) (map[string]interface{}, error)
+
+var migrations = map[int][]Migration{}
+
+// document is the top level envelope written around an object tree.
+// Canonical is set if fields holding their zero value were left out of the tree.
+type document struct {
+	Canonical bool `json:",omitempty"`
+	Version   int
+	Object    interface{}
+}
+
+// RegisterMigration adds a migration that upgrades documents at version `from` to `from+1`.
+// Migrations for the same version are run in the order that they were registered.
+func RegisterMigration(from int, m Migration) {
+	migrations[from] = append(migrations[from], m)
+}
+
+// RenameField returns a migration that renames a field in the "Struct" of every object of the given type,
+// for example when a field of a Fyne widget has been renamed.
+func RenameField(class, from, to string) Migration {
+	return func(root map[string]interface{}) (map[string]interface{}, error) {
+		walkJSONObjects(root, func(m map[string]interface{}) {
+			if m["Type"] != class {
+				return
+			}
+			info, ok := m["Struct"].(map[string]interface{})
+			if !ok {
+				return
+			}
+
+			if val, ok := info[from]; ok {
+				info[to] = val
+				delete(info, from)
+			}
+		})
+		return root, nil
+	}
+}
+
+// upgradeDocument unwraps a document envelope, if present, and runs any migrations required to bring
+// the object tree up to the current `FormatVersion`.
+func (dec *decoder) upgradeDocument(m map[string]interface{}) map[string]interface{} {
+	version := 0
+	root := m
+	if v, ok := m["Version"]; ok {
+		num, ok := v.(float64)
+		if !ok {
+			dec.report("Version", "", SeverityError, "Version should be a number")
+			return nil
+		}
+		version = int(num)
+		dec.canonical = m["Canonical"] == true
+
+		root, ok = m["Object"].(map[string]interface{})
+		if !ok {
+			dec.report("Object", "", SeverityError, "document does not contain an object")
+			return nil
+		}
+	}
+
+	if dec.checkNewer(version) {
+		return root
+	}
+
+	for ; version < FormatVersion; version++ {
+		for _, migrate := range migrations[version] {
+			next, err := migrate(root)
+			if err != nil {
+				dec.report("", "", SeverityError, fmt.Sprintf("failed to migrate from version %d: %v", version, err))
+				return nil
+			}
+			root = next
+		}
+	}
+
+	return root
+}
+
+// checkNewer reports a warning and returns true if the document version is newer than this package supports.
+func (dec *decoder) checkNewer(version int) bool {
+	if version <= FormatVersion {
+		return false
+	}
+
+	dec.report("Version", "", SeverityWarning,
+		fmt.Sprintf("document version %d is newer than supported version %d", version, FormatVersion))
+	return true
+}
+
+// migrationsPending returns true if any migrations are registered to upgrade a document from the given version.
+func migrationsPending(version int) bool {
+	for ; version < FormatVersion; version++ {
+		if len(migrations[version]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// walkJSONObjects calls fn for every JSON object in the tree of decoded data.
+func walkJSONObjects(data interface{}, fn func(map[string]interface{})) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		fn(v)
+		for _, child := range v {
+			walkJSONObjects(child, fn)
+		}
+	case []interface{}:
+		for _, child := range v {
+			walkJSONObjects(child, fn)
+		}
+	}
+}
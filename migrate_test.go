@@ -0,0 +1,59 @@
+package refyne
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeObjectVersioned(t *testing.T) {
+	l := widget.NewLabel("Hi")
+	ctx := DefaultContext()
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeObject(l, ctx, &buf))
+	assert.True(t, strings.HasPrefix(buf.String(), "{\n  \"Version\": 1,\n  \"Object\": {"))
+
+	obj, err := DecodeObject(&buf, DefaultContext())
+	require.NoError(t, err)
+	assert.Equal(t, "Hi", obj.(*widget.Label).Text)
+
+	// legacy documents have no envelope
+	obj, err = DecodeObject(strings.NewReader(labelJSONWith("")), DefaultContext())
+	require.NoError(t, err)
+	assert.Equal(t, "Hi", obj.(*widget.Label).Text)
+}
+
+func TestDecodeObjectMigration(t *testing.T) {
+	defer func(old map[int][]Migration) {
+		migrations = old
+	}(migrations)
+	migrations = map[int][]Migration{}
+
+	RegisterMigration(0, RenameField("*widget.Label", "Caption", "Text"))
+
+	in := `{"Type": "*fyne.Container", "Layout": "VBox", "Objects": [
+  {"Type": "*widget.Label", "Struct": {"Caption": "Old"}}
+]}`
+	obj, err := DecodeObject(strings.NewReader(in), DefaultContext())
+	require.NoError(t, err)
+	l := obj.(*fyne.Container).Objects[0].(*widget.Label)
+	assert.Equal(t, "Old", l.Text)
+}
+
+func TestDecodeObjectNewerVersion(t *testing.T) {
+	in := `{"Version": 99, "Object": ` + labelJSONWith("") + `}`
+	obj, err := DecodeObject(strings.NewReader(in), DefaultContext())
+	assert.Equal(t, "Hi", obj.(*widget.Label).Text)
+
+	var decErr *DecodeError
+	require.ErrorAs(t, err, &decErr)
+	assert.False(t, decErr.HasErrors())
+	assert.Equal(t, "Version", decErr.Issues[0].Path)
+}
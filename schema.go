@@ -0,0 +1,408 @@
+package refyne
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2/canvas"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema describing the documents written by `EncodeObject`.
+// It is generated from the registered widgets, containers, collections, graphics and layouts,
+// so any types added with `RegisterWidget` and similar will be included.
+func JSONSchema() map[string]interface{} {
+	return JSONSchemaFor(DefaultContext())
+}
+
+// JSONSchemaFor is like `JSONSchema` but describes the types and icons registered for the context.
+func JSONSchemaFor(d Context) map[string]interface{} {
+	guidefs.InitOnce()
+	reg := guidefs.RegistryOf(d)
+
+	defs := map[string]interface{}{
+		"document": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"Version", "Object"},
+			"properties": map[string]interface{}{
+				"Canonical": map[string]interface{}{
+					"description": "set if fields holding the zero value of their type were left out",
+					"type":        "boolean",
+				},
+				"Version": map[string]interface{}{"type": "integer", "minimum": 0, "maximum": FormatVersion},
+				"Object":  schemaRef("object"),
+			},
+		},
+		"resource": map[string]interface{}{
+			"description": "the name of a theme icon, or of a custom resource registered in the context",
+			"type":        "string",
+			"examples":    reg.IconNames(),
+		},
+		"color": map[string]interface{}{
+			"oneOf": []interface{}{
+				schemaObject(map[string]interface{}{
+					"R": map[string]interface{}{"type": "integer"},
+					"G": map[string]interface{}{"type": "integer"},
+					"B": map[string]interface{}{"type": "integer"},
+					"A": map[string]interface{}{"type": "integer"},
+				}),
+				schemaObject(map[string]interface{}{
+					"Y": map[string]interface{}{"type": "integer"},
+				}),
+			},
+		},
+		"properties": map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+		},
+	}
+
+	var types []string
+	var rules []interface{}
+	add := func(class string, def map[string]interface{}) {
+		id := schemaID(class)
+		types = append(types, class)
+		defs[id] = def
+		rules = append(rules, map[string]interface{}{
+			"if":   schemaObject(map[string]interface{}{"Type": map[string]interface{}{"const": class}}),
+			"then": schemaRef(id),
+		})
+	}
+
+	add("*fyne.Container", containerSchema(reg))
+	add(componentType, componentSchema())
+	for class, def := range containerStructSchemas() {
+		add(class, nodeSchema(class, def, false))
+	}
+	for _, names := range [][]string{reg.WidgetNames(), reg.CollectionNames(), reg.ContainerNames(), reg.GraphicsNames()} {
+		for _, class := range names {
+			if _, ok := defs[schemaID(class)]; ok {
+				continue
+			}
+			fields, ok := widgetStructSchema(class, d)
+			if !ok {
+				continue // an alias such as "*widget.PasswordEntry" is stored as the underlying type
+			}
+			add(class, nodeSchema(class, fields, true))
+		}
+	}
+
+	sort.Strings(types)
+	sort.Slice(rules, func(i, j int) bool {
+		return schemaRuleType(rules[i]) < schemaRuleType(rules[j])
+	})
+	defs["object"] = map[string]interface{}{
+		"type":     "object",
+		"required": []string{"Type"},
+		"properties": map[string]interface{}{
+			"Type": map[string]interface{}{"enum": types},
+		},
+		"allOf": rules,
+	}
+
+	return map[string]interface{}{
+		"$schema": schemaDraft,
+		"title":   "Fyne GUI document",
+		"oneOf":   []interface{}{schemaRef("document"), schemaRef("object")},
+		"$defs":   defs,
+	}
+}
+
+// WriteJSONSchema writes the JSON Schema returned by `JSONSchema` to the provided writer.
+func WriteJSONSchema(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(JSONSchema())
+}
+
+func containerSchema(reg *Registry) map[string]interface{} {
+	names := append([]string(nil), reg.LayoutNames()...)
+	var rules []interface{}
+	for _, name := range names {
+		info, _ := reg.Layout(name)
+		if len(info.Properties) == 0 {
+			continue
+		}
+
+		props := map[string]interface{}{}
+		for key, desc := range info.Properties {
+			props[key] = map[string]interface{}{"type": "string", "description": desc}
+		}
+		rules = append(rules, map[string]interface{}{
+			"if": schemaObject(map[string]interface{}{"Layout": map[string]interface{}{"const": name}}),
+			"then": schemaObject(map[string]interface{}{
+				"Properties": schemaObject(props),
+			}),
+		})
+	}
+	sort.Strings(names)
+	sort.Slice(rules, func(i, j int) bool {
+		return schemaRuleLayout(rules[i]) < schemaRuleLayout(rules[j])
+	})
+
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"Type"},
+		"properties": map[string]interface{}{
+			"Type":       map[string]interface{}{"const": "*fyne.Container"},
+			"Name":       map[string]interface{}{"type": "string"},
+			"Layout":     map[string]interface{}{"enum": names},
+			"Objects":    map[string]interface{}{"type": "array", "items": schemaRef("object")},
+			"Properties": schemaRef("properties"),
+		},
+		"allOf": rules,
+	}
+}
+
+// componentSchema describes a reference to another GUI document, with field values for the objects named inside it.
+func componentSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"Type", "Component"},
+		"properties": map[string]interface{}{
+			"Type":      map[string]interface{}{"const": componentType},
+			"Component": map[string]interface{}{"type": "string", "description": "the path or name of the GUI document"},
+			"Overrides": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "object"},
+			},
+		},
+	}
+}
+
+// containerStructSchemas returns the "Struct" schema for types that are encoded with custom fields.
+func containerStructSchemas() map[string]map[string]interface{} {
+	object := schemaRef("object")
+	str := map[string]interface{}{"type": "string"}
+	items := func(item map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"type": "array", "items": schemaObject(item)}
+	}
+
+	return map[string]map[string]interface{}{
+		"*container.AppTabs": {
+			"Items": items(map[string]interface{}{
+				"Text":    str,
+				"Icon":    schemaRef("resource"),
+				"Content": object,
+			}),
+			"SelectedIndex": map[string]interface{}{"type": "integer"},
+			"TabLocation":   map[string]interface{}{"enum": []string{"", "Top", "Bottom", "Leading", "Trailing"}},
+		},
+		"*container.Clip": {"Content": object},
+		"*container.Navigation": {
+			"Title": str,
+			"Root":  object,
+		},
+		"*container.Scroll": {
+			"Direction": map[string]interface{}{"type": "integer"},
+			"Content":   object,
+		},
+		"*container.Split": {
+			"Horizontal": map[string]interface{}{"type": "boolean"},
+			"Offset":     map[string]interface{}{"type": "number"},
+			"Leading":    object,
+			"Trailing":   object,
+		},
+		"*container.ThemeOverride": {
+			"Content": object,
+			"Theme":   map[string]interface{}{"type": "string", "description": "theme definition in Fyne JSON theme format"},
+		},
+		"*widget.Accordion": {
+			"Items": items(map[string]interface{}{
+				"Title":  str,
+				"Open":   map[string]interface{}{"type": "boolean"},
+				"Detail": object,
+			}),
+			"MultiOpen": map[string]interface{}{"type": "boolean"},
+		},
+		"*widget.Card": {
+			"Title":    str,
+			"Subtitle": str,
+			"Image":    cardImageSchema(),
+			"Content":  object,
+		},
+		"*widget.Form": {
+			"Hidden": map[string]interface{}{"type": "boolean"},
+			"Items": items(map[string]interface{}{
+				"HintText": str,
+				"Text":     str,
+				"Widget":   object,
+			}),
+			"SubmitText": str,
+			"CancelText": str,
+		},
+		"*widget.Toolbar": {
+			"Items": items(map[string]interface{}{
+				"Type": map[string]interface{}{"enum": []string{"Action", "Separator", "Spacer"}},
+				"Icon": schemaRef("resource"),
+			}),
+		},
+	}
+}
+
+// cardImageSchema describes the image of a Card, which is written as the fields of a *canvas.Image, or null.
+// The resource of the image is written as its own fields, rather than by name.
+func cardImageSchema() map[string]interface{} {
+	null := map[string]interface{}{"type": "null"}
+	image := schemaForType(reflect.TypeOf(canvas.Image{}))
+	image["properties"].(map[string]interface{})["Resource"] = map[string]interface{}{
+		"oneOf": []interface{}{map[string]interface{}{"type": "object"}, null},
+	}
+
+	return map[string]interface{}{"oneOf": []interface{}{image, null}}
+}
+
+func nodeSchema(class string, fields map[string]interface{}, actions bool) map[string]interface{} {
+	props := map[string]interface{}{
+		"Type":       map[string]interface{}{"const": class},
+		"Name":       map[string]interface{}{"type": "string"},
+		"Struct":     schemaObject(fields),
+		"Properties": schemaRef("properties"),
+	}
+	if actions {
+		props["Actions"] = map[string]interface{}{
+			"type":                 "object",
+			"description":          "callbacks by field name, i.e. OnTapped",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+		}
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"required":   []string{"Type"},
+		"properties": props,
+	}
+}
+
+// widgetStructSchema describes the exported fields of a widget as they are written by encoding/json.
+// If the class is not encoded using its own name then false is returned.
+func widgetStructSchema(class string, d Context) (map[string]interface{}, bool) {
+	reg := guidefs.RegistryOf(d)
+	info := reg.Lookup(class)
+	fields := map[string]interface{}{}
+	if info == nil || info.Create == nil {
+		return fields, true
+	}
+
+	obj := info.Create(ContextWithRegistry(reg))
+	if reg.TypeName(obj) != class {
+		return nil, false
+	}
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fields, true
+	}
+
+	for _, f := range reflect.VisibleFields(t) {
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		if s := schemaForType(f.Type); s != nil {
+			fields[name] = s
+		}
+	}
+	return fields, true
+}
+
+func schemaForType(t reflect.Type) map[string]interface{} {
+	switch t.String() {
+	case "fyne.Resource":
+		return schemaRef("resource")
+	case "color.Color":
+		return schemaRef("color")
+	case "fyne.CanvasObject":
+		return schemaRef("object")
+	case "fyne.Position":
+		return schemaObject(map[string]interface{}{
+			"X": map[string]interface{}{"type": "number"},
+			"Y": map[string]interface{}{"type": "number"},
+		})
+	case "time.Time", "*time.Time":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		items := schemaForType(t.Elem())
+		if items == nil {
+			return nil
+		}
+		return map[string]interface{}{"type": "array", "items": items}
+	case reflect.Ptr:
+		return schemaForType(t.Elem())
+	case reflect.Struct:
+		fields := map[string]interface{}{}
+		for _, f := range reflect.VisibleFields(t) {
+			if f.Anonymous || !f.IsExported() || f.Tag.Get("json") == "-" {
+				continue
+			}
+			if s := schemaForType(f.Type); s != nil {
+				fields[f.Name] = s
+			}
+		}
+		return schemaObject(fields)
+	case reflect.Map, reflect.Interface:
+		return map[string]interface{}{}
+	}
+
+	return nil // functions and channels are not encoded
+}
+
+func schemaID(class string) string {
+	return strings.TrimPrefix(class, "*")
+}
+
+func schemaObject(props map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+	}
+}
+
+func schemaRef(id string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/$defs/" + id}
+}
+
+func schemaRuleType(rule interface{}) string {
+	return schemaRuleConst(rule, "Type")
+}
+
+func schemaRuleLayout(rule interface{}) string {
+	return schemaRuleConst(rule, "Layout")
+}
+
+func schemaRuleConst(rule interface{}, key string) string {
+	cond := rule.(map[string]interface{})["if"].(map[string]interface{})
+	prop := cond["properties"].(map[string]interface{})[key].(map[string]interface{})
+	return prop["const"].(string)
+}
@@ -0,0 +1,58 @@
+package refyne
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema()
+	assert.Equal(t, schemaDraft, schema["$schema"])
+
+	defs := schema["$defs"].(map[string]interface{})
+	require.Contains(t, defs, "widget.Label")
+	require.Contains(t, defs, "container.Split")
+	assert.NotContains(t, defs, "widget.PasswordEntry")
+
+	label := defs["widget.Label"].(map[string]interface{})["properties"].(map[string]interface{})
+	fields := label["Struct"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string"}, fields["Text"])
+	assert.NotContains(t, fields, "BaseWidget")
+
+	cont := defs["fyne.Container"].(map[string]interface{})
+	layouts := cont["properties"].(map[string]interface{})["Layout"].(map[string]interface{})["enum"]
+	assert.Contains(t, layouts, "Border")
+	assert.Contains(t, layouts, "GridWrap")
+
+	props := map[string][]string{}
+	for _, r := range cont["allOf"].([]interface{}) {
+		rule := r.(map[string]interface{})
+		layout := schemaRuleLayout(rule)
+		then := rule["then"].(map[string]interface{})["properties"].(map[string]interface{})
+		for key := range then["Properties"].(map[string]interface{})["properties"].(map[string]interface{}) {
+			props[layout] = append(props[layout], key)
+		}
+	}
+	assert.ElementsMatch(t, []string{"top", "bottom", "left", "right"}, props["Border"])
+	assert.ElementsMatch(t, []string{"width", "height"}, props["GridWrap"])
+
+	card := defs["widget.Card"].(map[string]interface{})["properties"].(map[string]interface{})
+	fields = card["Struct"].(map[string]interface{})["properties"].(map[string]interface{})
+	image := fields["Image"].(map[string]interface{})["oneOf"].([]interface{})
+	require.Len(t, image, 2)
+	assert.Equal(t, "object", image[0].(map[string]interface{})["type"])
+	assert.Contains(t, image[0].(map[string]interface{})["properties"], "FillMode")
+	assert.Equal(t, map[string]interface{}{"type": "null"}, image[1])
+}
+
+func TestWriteJSONSchema(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteJSONSchema(buf))
+
+	var data map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+}
@@ -0,0 +1,255 @@
+package refyne
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+)
+
+// DecodeProgress describes how much of a document has been read by `DecodeObjectStream`.
+type DecodeProgress struct {
+	// Objects is the number of objects that have been created so far.
+	Objects int
+	// Offset is the number of bytes of input that have been read.
+	Offset int64
+}
+
+// decodedObject holds an object that was created while streaming, in place of its JSON data.
+type decodedObject struct {
+	obj fyne.CanvasObject
+	ctx Context
+}
+
+// MarshalJSON encodes the object again, which is needed if it is kept inside a placeholder.
+func (o *decodedObject) MarshalJSON() ([]byte, error) {
+	tree, err := EncodeMap(o.obj, o.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tree)
+}
+
+// DecodeObjectStream returns a tree of `CanvasObject` elements from the provided JSON `Reader`, like `DecodeObject`.
+// Rather than loading the whole document first each object is created as soon as it has been read,
+// so large documents use less memory and `DecodeOptions.Progress` can be used to report progress.
+// If the context is cancelled decoding stops and the context error is returned.
+// Any problems are reported in the same way as `DecodeObject`, though not always in document order.
+//
+// Documents that need migrating are read in full before decoding, as migrations work on the whole tree.
+// If migrations are registered and the object comes before the version, as in canonical documents,
+// the JSON of the object is held until the version has been read.
+func DecodeObjectStream(ctx gocontext.Context, r io.Reader, d Context, opts DecodeOptions) (fyne.CanvasObject, error) {
+	guidefs.InitOnce()
+
+	s := &streamDecoder{
+		decoder: &decoder{ctx: d, opts: opts, dir: opts.Dir},
+		cancel:  ctx,
+		json:    json.NewDecoder(r),
+		eager:   !migrationsPending(0),
+	}
+	data, err := s.readRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	switch root := data.(type) {
+	case nil:
+		return nil, nil
+	case *decodedObject:
+		return root.obj, s.err()
+	case map[string]interface{}:
+		if built, ok := root["Object"].(*decodedObject); ok {
+			if v, ok := root["Version"].(float64); ok {
+				s.checkNewer(int(v))
+			}
+			return built.obj, s.err()
+		}
+
+		m := s.upgradeDocument(root)
+		if m == nil {
+			return nil, s.err()
+		}
+		obj := s.decodeMap(m, "")
+		s.progress()
+		return obj, s.err()
+	}
+
+	s.report("", "", SeverityError, "document root is not an object")
+	return nil, s.err()
+}
+
+type streamDecoder struct {
+	*decoder
+
+	cancel gocontext.Context
+	json   *json.Decoder
+
+	// eager is set when objects can be created as they are read, which is not possible if migrations are needed.
+	eager   bool
+	objects int
+	offset  int64 // the input offset of the JSON being read, if it was held back from the input
+}
+
+// readRoot reads the top level of a document, which may be an envelope or, for older files, the root object.
+func (s *streamDecoder) readRoot() (interface{}, error) {
+	tok, err := s.json.Token()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return s.finishValue(tok, "")
+	}
+
+	m := map[string]interface{}{}
+	var object json.RawMessage // the object of an envelope that was read before its version
+	for s.json.More() {
+		key, err := s.readKey()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := m["Version"]; !ok && key == "Object" && migrationsPending(0) {
+			// canonical documents sort "Object" first, keep only its JSON until we know if it will be migrated
+			s.offset = s.json.InputOffset()
+			if err = s.json.Decode(&object); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		path := key
+		if key == "Object" {
+			path = "" // paths inside the envelope start from the root object
+		}
+		val, err := s.readValue(path)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = val
+
+		if v, ok := val.(float64); ok && key == "Version" {
+			s.eager = !migrationsPending(int(v))
+		} else if key == "Canonical" {
+			s.canonical = val == true
+		}
+	}
+	if _, err = s.json.Token(); err != nil {
+		return nil, err
+	}
+
+	if object != nil {
+		if _, ok := m["Version"]; !ok {
+			s.eager = false // not an envelope, so the object is part of a legacy document
+		}
+		input := s.json
+		s.json = json.NewDecoder(bytes.NewReader(object))
+		val, err := s.readValue("")
+		s.json = input
+		if err != nil {
+			return nil, err
+		}
+		m["Object"] = val
+		s.offset = 0
+	}
+
+	if _, ok := m["Object"]; !ok {
+		return s.finishObject(m, ""), nil
+	}
+	return m, nil
+}
+
+func (s *streamDecoder) readKey() (string, error) {
+	tok, err := s.json.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected token %v", tok)
+	}
+	return key, nil
+}
+
+func (s *streamDecoder) readValue(path string) (interface{}, error) {
+	tok, err := s.json.Token()
+	if err != nil {
+		return nil, err
+	}
+	return s.finishValue(tok, path)
+}
+
+// finishValue reads the rest of a value that starts with the given token.
+func (s *streamDecoder) finishValue(tok json.Token, path string) (interface{}, error) {
+	switch tok {
+	case json.Delim('{'):
+		if err := s.cancel.Err(); err != nil {
+			return nil, err
+		}
+
+		m := map[string]interface{}{}
+		for s.json.More() {
+			key, err := s.readKey()
+			if err != nil {
+				return nil, err
+			}
+			if m[key], err = s.readValue(joinPath(path, key)); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := s.json.Token(); err != nil {
+			return nil, err
+		}
+		return s.finishObject(m, path), nil
+	case json.Delim('['):
+		list := []interface{}{}
+		for s.json.More() {
+			item, err := s.readValue(indexPath(path, len(list)))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		if _, err := s.json.Token(); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+
+	return tok, nil
+}
+
+// finishObject creates the object described by the JSON map, if possible,
+// so that its data can be released and the parent only holds the result.
+func (s *streamDecoder) finishObject(m map[string]interface{}, path string) interface{} {
+	if !s.eager {
+		return m
+	}
+	class, ok := m["Type"].(string)
+	if !ok || (class != "*fyne.Container" && guidefs.RegistryOf(s.ctx).Lookup(class) == nil) {
+		return m // not an object, or an unknown type that is handled by the parent
+	}
+
+	obj := s.decodeMap(m, path)
+	if obj == nil {
+		return nil
+	}
+	s.progress()
+	return &decodedObject{obj: obj, ctx: s.ctx}
+}
+
+func (s *streamDecoder) progress() {
+	s.objects++
+	if s.opts.Progress == nil {
+		return
+	}
+
+	s.opts.Progress(DecodeProgress{Objects: s.objects, Offset: s.offset + s.json.InputOffset()})
+}
@@ -0,0 +1,131 @@
+package refyne
+
+import (
+	"bytes"
+	gocontext "context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func largeDocument(t *testing.T, count int) []byte {
+	c := container.NewVBox()
+	for i := 0; i < count; i++ {
+		c.Add(widget.NewLabel(fmt.Sprintf("Label %d", i)))
+	}
+	c.Add(container.NewHSplit(widget.NewButton("Left", nil), widget.NewEntry()))
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeObject(c, DefaultContext(), &buf))
+	return buf.Bytes()
+}
+
+func TestDecodeObjectStream(t *testing.T) {
+	data := largeDocument(t, 100)
+
+	var updates []DecodeProgress
+	opts := DecodeOptions{Progress: func(p DecodeProgress) {
+		updates = append(updates, p)
+	}}
+	obj, err := DecodeObjectStream(gocontext.Background(), bytes.NewReader(data), DefaultContext(), opts)
+	require.NoError(t, err)
+
+	c := obj.(*fyne.Container)
+	require.Len(t, c.Objects, 101)
+	assert.Equal(t, "Label 42", c.Objects[42].(*widget.Label).Text)
+	split := c.Objects[100].(*container.Split)
+	assert.Equal(t, "Left", split.Leading.(*widget.Button).Text)
+
+	require.Len(t, updates, 104)
+	for i := 1; i < len(updates); i++ {
+		assert.Equal(t, i+1, updates[i].Objects)
+		assert.GreaterOrEqual(t, updates[i].Offset, updates[i-1].Offset)
+	}
+
+	var fromStream, fromDecode bytes.Buffer
+	require.NoError(t, EncodeObject(obj, DefaultContext(), &fromStream))
+	decoded, err := DecodeObject(bytes.NewReader(data), DefaultContext())
+	require.NoError(t, err)
+	require.NoError(t, EncodeObject(decoded, DefaultContext(), &fromDecode))
+	assert.Equal(t, fromDecode.String(), fromStream.String())
+}
+
+func TestDecodeObjectStreamCancel(t *testing.T) {
+	data := largeDocument(t, 100)
+
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	opts := DecodeOptions{Progress: func(p DecodeProgress) {
+		if p.Objects == 10 {
+			cancel()
+		}
+	}}
+	obj, err := DecodeObjectStream(ctx, bytes.NewReader(data), DefaultContext(), opts)
+	assert.Nil(t, obj)
+	assert.ErrorIs(t, err, gocontext.Canceled)
+}
+
+func TestDecodeObjectStreamErrors(t *testing.T) {
+	in := `{
+  "Type": "*fyne.Container",
+  "Layout": "VBox",
+  "Objects": [
+    ` + labelJSONWith("    ") + `,
+    {"Type": "*widget.Unknown", "Struct": {}},
+    {"Type": "*widget.Label", "Struct": {"Text": 5}}
+  ]
+}`
+	obj, err := DecodeObjectStream(gocontext.Background(), strings.NewReader(in), DefaultContext(), DecodeOptions{})
+	require.NotNil(t, obj)
+	assert.Len(t, obj.(*fyne.Container).Objects, 2)
+
+	var decErr *DecodeError
+	require.ErrorAs(t, err, &decErr)
+	require.Len(t, decErr.Issues, 2)
+	paths := []string{decErr.Issues[0].Path, decErr.Issues[1].Path}
+	assert.ElementsMatch(t, []string{"Objects[1]", "Objects[2].Struct.Text"}, paths)
+
+	_, err = DecodeObjectStream(gocontext.Background(), strings.NewReader(`{"Type": "*fyne.Container", `),
+		DefaultContext(), DecodeOptions{})
+	assert.Error(t, err)
+}
+
+func TestDecodeObjectStreamCanonical(t *testing.T) {
+	defer func(old map[int][]Migration) {
+		migrations = old
+	}(migrations)
+	migrations = map[int][]Migration{}
+	RegisterMigration(0, RenameField("*widget.Label", "Caption", "Text"))
+
+	decoded, err := DecodeObject(bytes.NewReader(largeDocument(t, 100)), DefaultContext())
+	require.NoError(t, err)
+	var data bytes.Buffer
+	require.NoError(t, EncodeObjectWithOptions(decoded, DefaultContext(), &data, EncodeOptions{Canonical: true}))
+	require.Less(t, strings.Index(data.String(), `"Object"`), strings.Index(data.String(), `"Version"`))
+
+	var updates []DecodeProgress
+	opts := DecodeOptions{Progress: func(p DecodeProgress) {
+		updates = append(updates, p)
+	}}
+	obj, err := DecodeObjectStream(gocontext.Background(), bytes.NewReader(data.Bytes()), DefaultContext(), opts)
+	require.NoError(t, err)
+	require.Len(t, obj.(*fyne.Container).Objects, 101)
+	assert.Equal(t, "Label 42", obj.(*fyne.Container).Objects[42].(*widget.Label).Text)
+
+	require.Len(t, updates, 104) // objects were created as they were read
+	for i := 1; i < len(updates); i++ {
+		assert.Greater(t, updates[i].Offset, updates[i-1].Offset)
+	}
+	assert.LessOrEqual(t, updates[len(updates)-1].Offset, int64(data.Len()))
+
+	old := `{"Object": {"Type": "*widget.Label", "Struct": {"Caption": "Old"}}, "Version": 0}`
+	obj, err = DecodeObjectStream(gocontext.Background(), strings.NewReader(old), DefaultContext(), DecodeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Old", obj.(*widget.Label).Text)
+}
@@ -0,0 +1,221 @@
+package refyne
+
+import (
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+)
+
+// InsertChild adds an object to the children of a container, at the given index.
+// Containers that hold a fixed number of objects, such as a Scroll or Split, cannot have children inserted
+// unless they are empty, use `ReplaceChild` to change their content instead.
+func InsertChild(parent fyne.CanvasObject, index int, child fyne.CanvasObject, d Context) error {
+	info, children, err := treeInfo(parent, d)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index > len(children) {
+		return fmt.Errorf("index %d out of range for %d children", index, len(children))
+	}
+	if containsObject(child, parent, d) {
+		return errors.New("object cannot be added inside itself")
+	}
+
+	if info.InsertChild == nil {
+		if len(children) == 0 && info.AddChild != nil {
+			info.AddChild(parent, child)
+			return nil
+		}
+		return errors.New(info.Name + " does not support inserting children")
+	}
+	info.InsertChild(parent, index, child, d)
+	return nil
+}
+
+// RemoveChild removes an object from the children of a container.
+// The metadata of the object, and of any objects inside it, is removed from the context.
+func RemoveChild(parent, child fyne.CanvasObject, d Context) error {
+	if err := removeChild(parent, child, d); err != nil {
+		return err
+	}
+
+	forgetObject(child, d)
+	return nil
+}
+
+// MoveChild moves an object from one container to another, or to a different position in the same container.
+// The index is the position in the new parent once the object has been removed from its old parent.
+// Any layout settings that refer to the object by index, such as the edges of a Border, move with it.
+// An error is returned if the new parent is the object itself or inside it.
+func MoveChild(from, child, to fyne.CanvasObject, index int, d Context) error {
+	fromInfo, fromChildren, err := treeInfo(from, d)
+	if err != nil {
+		return err
+	}
+	old := indexOf(child, fromChildren)
+	if old == -1 {
+		return errors.New("object is not a child of the container")
+	}
+	if fromInfo.RemoveChild == nil {
+		return errors.New(fromInfo.Name + " does not support removing children")
+	}
+	toInfo, toChildren, err := treeInfo(to, d)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		toChildren = toChildren[:len(toChildren)-1]
+	}
+	if index < 0 || index > len(toChildren) {
+		return fmt.Errorf("index %d out of range for %d children", index, len(toChildren))
+	}
+	if containsObject(child, to, d) {
+		return errors.New("object cannot be moved inside itself")
+	}
+	if toInfo.InsertChild == nil {
+		return errors.New(toInfo.Name + " does not support inserting children")
+	}
+
+	var keys []string
+	if c, ok := from.(*fyne.Container); ok && from == to {
+		keys = guidefs.ChildIndexKeys(c, old, d)
+	}
+	fromInfo.RemoveChild(from, old, d)
+	toInfo.InsertChild(to, index, child, d)
+	if len(keys) > 0 {
+		guidefs.SetChildIndexKeys(to.(*fyne.Container), index, keys, d)
+	}
+	return nil
+}
+
+// ReplaceChild puts a new object in the place of a child of a container.
+// The metadata of the old object, and of any objects inside it, is removed from the context.
+func ReplaceChild(parent, old, replacement fyne.CanvasObject, d Context) error {
+	if err := replaceChild(parent, old, replacement, d); err != nil {
+		return err
+	}
+
+	forgetObject(old, d)
+	return nil
+}
+
+// WrapChild replaces a child of a container with a new container, using the named layout, that holds the child.
+// The new container is returned so that it can be edited further.
+func WrapChild(parent, child fyne.CanvasObject, layout string, d Context) (*fyne.Container, error) {
+	lay, ok := guidefs.RegistryOf(d).Layout(layout)
+	if !ok {
+		return nil, errors.New("unknown layout " + layout)
+	}
+
+	wrap := &fyne.Container{Objects: []fyne.CanvasObject{child}}
+	d.Metadata()[wrap] = map[string]string{"layout": layout}
+	wrap.Layout = lay.Create(wrap, d)
+	if err := replaceChild(parent, child, wrap, d); err != nil {
+		delete(d.Metadata(), wrap)
+		return nil, err
+	}
+	return wrap, nil
+}
+
+func removeChild(parent, child fyne.CanvasObject, d Context) error {
+	info, children, err := treeInfo(parent, d)
+	if err != nil {
+		return err
+	}
+	index := indexOf(child, children)
+	if index == -1 {
+		return errors.New("object is not a child of the container")
+	}
+	if info.RemoveChild == nil {
+		return errors.New(info.Name + " does not support removing children")
+	}
+
+	info.RemoveChild(parent, index, d)
+	return nil
+}
+
+func replaceChild(parent, old, replacement fyne.CanvasObject, d Context) error {
+	info, children, err := treeInfo(parent, d)
+	if err != nil {
+		return err
+	}
+	index := indexOf(old, children)
+	if index == -1 {
+		return errors.New("object is not a child of the container")
+	}
+	if containsObject(replacement, parent, d) {
+		return errors.New("object cannot be added inside itself")
+	}
+	if info.ReplaceChild == nil {
+		return errors.New(info.Name + " does not support replacing children")
+	}
+
+	info.ReplaceChild(parent, index, replacement, d)
+	return nil
+}
+
+// treeInfo returns the type information and current children of a container.
+func treeInfo(parent fyne.CanvasObject, d Context) (*WidgetInfo, []fyne.CanvasObject, error) {
+	guidefs.InitOnce()
+
+	reg := guidefs.RegistryOf(d)
+	info := reg.Lookup(reg.TypeName(parent))
+	if c, ok := parent.(*fyne.Container); ok && info != nil {
+		return info, c.Objects, nil
+	}
+	if info == nil || !info.IsContainer() {
+		return nil, nil, errors.New("object is not a container")
+	}
+
+	return info, info.Children(parent), nil
+}
+
+// forgetObject removes the metadata of an object that has left the tree, and of the objects inside it.
+func forgetObject(obj fyne.CanvasObject, d Context) {
+	if obj == nil {
+		return
+	}
+
+	if c, ok := obj.(*fyne.Container); ok {
+		for _, child := range c.Objects {
+			forgetObject(child, d)
+		}
+	} else if info := guidefs.RegistryOf(d).Lookup(guidefs.RegistryOf(d).TypeName(obj)); info != nil && info.IsContainer() {
+		for _, child := range info.Children(obj) {
+			forgetObject(child, d)
+		}
+	}
+
+	delete(d.Metadata(), obj)
+	delete(d.Attrs(), obj)
+}
+
+// containsObject returns true if the object is the root, or is inside the tree of objects under the root.
+func containsObject(root, obj fyne.CanvasObject, d Context) bool {
+	if root == nil {
+		return false
+	}
+	if root == obj {
+		return true
+	}
+
+	_, children, _ := treeInfo(root, d)
+	for _, child := range children {
+		if containsObject(child, obj, d) {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOf(obj fyne.CanvasObject, objs []fyne.CanvasObject) int {
+	for i, o := range objs {
+		if o == obj {
+			return i
+		}
+	}
+	return -1
+}
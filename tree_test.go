@@ -0,0 +1,110 @@
+package refyne
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBorder(d Context, objs ...fyne.CanvasObject) *fyne.Container {
+	c := &fyne.Container{Objects: objs}
+	d.Metadata()[c] = map[string]string{"layout": "Border", "top": "0", "bottom": "2"}
+	c.Layout = guidefs.Layouts["Border"].Create(c, d)
+	return c
+}
+
+func TestInsertRemoveChild(t *testing.T) {
+	d := DefaultContext()
+	top, middle, bottom := widget.NewLabel("top"), widget.NewLabel("middle"), widget.NewLabel("bottom")
+	c := newTestBorder(d, top, middle, bottom)
+
+	extra := widget.NewLabel("extra")
+	require.NoError(t, InsertChild(c, 0, extra, d))
+	assert.Equal(t, []fyne.CanvasObject{extra, top, middle, bottom}, c.Objects)
+	assert.Equal(t, "1", d.Metadata()[c]["top"])
+	assert.Equal(t, "3", d.Metadata()[c]["bottom"])
+
+	d.Metadata()[top] = map[string]string{"name": "heading"}
+	require.NoError(t, RemoveChild(c, top, d))
+	assert.Equal(t, []fyne.CanvasObject{extra, middle, bottom}, c.Objects)
+	assert.NotContains(t, d.Metadata()[c], "top")
+	assert.Equal(t, "2", d.Metadata()[c]["bottom"])
+	assert.NotContains(t, d.Metadata(), top)
+
+	assert.Error(t, RemoveChild(c, top, d))
+	assert.Error(t, InsertChild(c, 5, top, d))
+	assert.Error(t, InsertChild(widget.NewLabel("leaf"), 0, top, d))
+}
+
+func TestMoveChild(t *testing.T) {
+	d := DefaultContext()
+	top, middle, bottom := widget.NewLabel("top"), widget.NewLabel("middle"), widget.NewLabel("bottom")
+	c := newTestBorder(d, top, middle, bottom)
+
+	require.NoError(t, MoveChild(c, bottom, c, 0, d))
+	assert.Equal(t, []fyne.CanvasObject{bottom, top, middle}, c.Objects)
+	assert.Equal(t, "1", d.Metadata()[c]["top"])
+	assert.Equal(t, "0", d.Metadata()[c]["bottom"])
+
+	tabs := container.NewAppTabs(container.NewTabItem("One", widget.NewLabel("one")))
+	d.Metadata()[middle] = map[string]string{"name": "kept"}
+	require.NoError(t, MoveChild(c, middle, tabs, 0, d))
+	assert.Len(t, c.Objects, 2)
+	require.Len(t, tabs.Items, 2)
+	assert.Equal(t, middle, tabs.Items[0].Content)
+	assert.Equal(t, "kept", d.Metadata()[middle]["name"])
+
+	assert.Error(t, MoveChild(c, top, container.NewScroll(widget.NewLabel("full")), 0, d))
+	assert.Equal(t, top, c.Objects[1])
+}
+
+func TestMoveChildIntoItself(t *testing.T) {
+	d := DefaultContext()
+	leaf := widget.NewLabel("leaf")
+	inner := container.NewVBox(leaf)
+	d.Metadata()[inner] = map[string]string{"layout": "VBox"}
+	middle := container.NewVBox(inner)
+	d.Metadata()[middle] = map[string]string{"layout": "VBox"}
+	outer := container.NewVBox(middle)
+	d.Metadata()[outer] = map[string]string{"layout": "VBox"}
+
+	assert.Error(t, MoveChild(outer, middle, middle, 0, d))
+	assert.Error(t, MoveChild(outer, middle, inner, 0, d))
+	assert.Error(t, InsertChild(inner, 0, middle, d))
+	assert.Error(t, ReplaceChild(inner, leaf, outer, d))
+	assert.Equal(t, []fyne.CanvasObject{middle}, outer.Objects)
+	assert.Equal(t, []fyne.CanvasObject{inner}, middle.Objects)
+	assert.Equal(t, []fyne.CanvasObject{leaf}, inner.Objects)
+}
+
+func TestReplaceAndWrapChild(t *testing.T) {
+	d := DefaultContext()
+	left, right := widget.NewLabel("left"), widget.NewLabel("right")
+	split := container.NewHSplit(left, right)
+
+	replacement := widget.NewButton("new", nil)
+	require.NoError(t, ReplaceChild(split, right, replacement, d))
+	assert.Equal(t, replacement, split.Trailing)
+
+	wrap, err := WrapChild(split, left, "VBox", d)
+	require.NoError(t, err)
+	assert.Equal(t, wrap, split.Leading)
+	assert.Equal(t, []fyne.CanvasObject{left}, wrap.Objects)
+	assert.Equal(t, "VBox", d.Metadata()[wrap]["layout"])
+
+	form := widget.NewForm(widget.NewFormItem("Name", widget.NewEntry()))
+	entry := form.Items[0].Widget
+	require.NoError(t, InsertChild(form, 1, replacement, d))
+	require.NoError(t, RemoveChild(form, entry, d))
+	require.Len(t, form.Items, 1)
+	assert.Equal(t, replacement, form.Items[0].Widget)
+
+	_, err = WrapChild(split, wrap, "Unknown", d)
+	assert.Error(t, err)
+}
@@ -0,0 +1,218 @@
+package refyne
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+)
+
+// Issue describes a problem found by `Validate` in an object tree.
+type Issue struct {
+	// Object is the object that has the problem.
+	Object   fyne.CanvasObject
+	Severity Severity
+	// Type is the type name of the object, i.e. "*widget.Button".
+	Type    string
+	Message string
+}
+
+// String returns a single line summary of the issue.
+func (i Issue) String() string {
+	if i.Type == "" {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Type, i.Message)
+}
+
+// Validate checks an object tree for problems that would stop the code from `ExportGo` building,
+// or the GUI from working as expected, without exporting or running it.
+// Errors are reported for names and actions that are not valid in Go code, duplicate names, names that are
+// used by the generated code, such as "win", "makeUI" or a handler method, and Border layouts that refer
+// to objects that do not exist.
+// Warnings are reported for settings that are ignored, such as unknown layouts, GridWrap sizes that are not numbers,
+// empty AppTabs and images that have no file or resource.
+// The objects inside a component are checked when the component's own document is validated.
+func Validate(obj fyne.CanvasObject, d Context) []Issue {
+	return validate(obj, d, nil)
+}
+
+// ValidateExport checks an object tree like `Validate`, and also reports names that are the same as the
+// GUI type or constructor that `ExportGoWithOptions` would declare for the given name and options.
+func ValidateExport(obj fyne.CanvasObject, d Context, name string, opts ExportOptions) []Issue {
+	typeName, constructor, err := exportNames(name, opts)
+	if err != nil {
+		return []Issue{{Severity: SeverityError, Message: err.Error()}}
+	}
+
+	return validate(obj, d, map[string]string{
+		typeName:    "the GUI type",
+		constructor: "the GUI constructor",
+	})
+}
+
+func validate(obj fyne.CanvasObject, d Context, reserved map[string]string) []Issue {
+	guidefs.InitOnce()
+
+	v := &validator{ctx: d, names: make(map[string]fyne.CanvasObject), handlers: make(map[string]fyne.CanvasObject),
+		reserved: map[string]string{"win": "the window field", "makeUI": "the makeUI method"}}
+	for name, use := range reserved {
+		v.reserved[name] = use
+	}
+	v.walk(obj)
+	v.checkHandlers()
+	return v.issues
+}
+
+type validator struct {
+	ctx      Context
+	names    map[string]fyne.CanvasObject
+	handlers map[string]fyne.CanvasObject // the first object with an action that calls each method of the GUI type
+	reserved map[string]string            // the identifiers declared by the generated code and what they are used for
+	issues   []Issue
+}
+
+func (v *validator) report(obj fyne.CanvasObject, sev Severity, msg string) {
+	class := ""
+	if obj != nil {
+		class = guidefs.RegistryOf(v.ctx).TypeName(obj)
+	}
+	v.issues = append(v.issues, Issue{Object: obj, Severity: sev, Type: class, Message: msg})
+}
+
+func (v *validator) walk(o fyne.CanvasObject) {
+	if o == nil || guidefs.IsComponent(o, v.ctx) {
+		return
+	}
+
+	props := v.ctx.Metadata()[o]
+	v.checkName(o, props["name"])
+	v.checkActions(o, props)
+	switch w := o.(type) {
+	case *fyne.Container:
+		v.checkLayout(w, props)
+	case *container.AppTabs:
+		if len(w.Items) == 0 {
+			v.report(o, SeverityWarning, "AppTabs has no tabs")
+		}
+	case *canvas.Image:
+		if w.File == "" && w.Resource == nil {
+			v.report(o, SeverityWarning, "Image has no File or Resource")
+		}
+	}
+
+	for _, child := range childObjects(o, v.ctx) {
+		v.walk(child)
+	}
+}
+
+func (v *validator) checkName(o fyne.CanvasObject, name string) {
+	if name == "" {
+		return
+	}
+
+	switch {
+	case token.IsKeyword(name):
+		v.report(o, SeverityError, "name "+name+" is a Go keyword")
+	case !token.IsIdentifier(name):
+		v.report(o, SeverityError, "name "+name+" is not a valid Go identifier")
+	case v.reserved[name] != "":
+		v.report(o, SeverityError, "name "+name+" is used for "+v.reserved[name])
+	}
+
+	if _, ok := v.names[name]; ok {
+		v.report(o, SeverityError, "name "+name+" is used by another object")
+		return
+	}
+	v.names[name] = o
+}
+
+// checkActions reports actions that are not a reference to a function, such as "g.onLogin".
+func (v *validator) checkActions(o fyne.CanvasObject, props map[string]string) {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		if len(k) > 2 && k[0:2] == "On" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys) // report issues in a stable order
+
+	for _, k := range keys {
+		action := props[k]
+		if action == "" {
+			continue
+		}
+		for _, part := range strings.Split(action, ".") {
+			if !token.IsIdentifier(part) {
+				v.report(o, SeverityError, fmt.Sprintf("action %s of %s is not a function name", action, k))
+				break
+			}
+		}
+		if strings.HasPrefix(action, "g.") {
+			if _, ok := v.handlers[action[2:]]; !ok {
+				v.handlers[action[2:]] = o
+			}
+		}
+	}
+}
+
+// checkHandlers reports names that are the same as a method of the GUI type referred to by an action,
+// as the generated struct cannot have both a field and a method with that name.
+// This is run once all of the names in the tree are known.
+func (v *validator) checkHandlers() {
+	methods := make([]string, 0, len(v.handlers))
+	for name := range v.handlers {
+		methods = append(methods, name)
+	}
+	sort.Strings(methods)
+
+	for _, name := range methods {
+		if name == "makeUI" {
+			v.report(v.handlers[name], SeverityError, "action g.makeUI calls the makeUI method")
+		} else if o, ok := v.names[name]; ok {
+			v.report(o, SeverityError, "name "+name+" is used for the handler of action g."+name)
+		}
+	}
+}
+
+func (v *validator) checkLayout(c *fyne.Container, props map[string]string) {
+	name := props["layout"]
+	if name == "" {
+		return
+	}
+	if _, ok := guidefs.RegistryOf(v.ctx).Layout(name); !ok {
+		v.report(c, SeverityWarning, "undefined layout "+name+", using Stack")
+		return
+	}
+
+	switch name {
+	case "Border":
+		for _, key := range []string{"top", "bottom", "left", "right"} {
+			if props[key] == "" {
+				continue
+			}
+			i, err := strconv.Atoi(props[key])
+			if err != nil || i < 0 || i >= len(c.Objects) {
+				v.report(c, SeverityError,
+					fmt.Sprintf("Border %s is %s, which is not the index of one of the %d objects", key, props[key], len(c.Objects)))
+			}
+		}
+	case "GridWrap":
+		for _, key := range []string{"width", "height"} {
+			if props[key] == "" {
+				continue
+			}
+			if _, err := strconv.ParseInt(props[key], 0, 0); err != nil {
+				v.report(c, SeverityWarning, fmt.Sprintf("GridWrap %s %s is not a number, using 100", key, props[key]))
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package refyne
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	d := DefaultContext()
+	ok := widget.NewButton("OK", nil)
+	d.Metadata()[ok] = map[string]string{"name": "okButton", "OnTapped": "g.onOK"}
+	border := container.NewWithoutLayout(ok)
+	d.Metadata()[border] = map[string]string{"layout": "Border", "top": "0"}
+	border.Layout = guidefs.Layouts["Border"].Create(border, d)
+
+	assert.Empty(t, Validate(border, d))
+
+	cancel := widget.NewButton("Cancel", nil)
+	d.Metadata()[cancel] = map[string]string{"name": "okButton", "OnTapped": "g.on Cancel()"}
+	label := widget.NewLabel("")
+	d.Metadata()[label] = map[string]string{"name": "func"}
+	img := &canvas.Image{}
+	tabs := container.NewAppTabs()
+	grid := container.NewWithoutLayout(img, tabs)
+	d.Metadata()[grid] = map[string]string{"layout": "GridWrap", "width": "wide"}
+	border.Objects = append(border.Objects, cancel, label, grid)
+	d.Metadata()[border]["bottom"] = "4"
+
+	issues := Validate(border, d)
+	messages := make([]string, len(issues))
+	for i, issue := range issues {
+		messages[i] = issue.String()
+	}
+	assert.Equal(t, []string{
+		"error: *fyne.Container: Border bottom is 4, which is not the index of one of the 4 objects",
+		"error: *widget.Button: name okButton is used by another object",
+		"error: *widget.Button: action g.on Cancel() of OnTapped is not a function name",
+		"error: *widget.Label: name func is a Go keyword",
+		"warning: *fyne.Container: GridWrap width wide is not a number, using 100",
+		"warning: *canvas.Image: Image has no File or Resource",
+		"warning: *container.AppTabs: AppTabs has no tabs",
+	}, messages)
+	assert.Equal(t, cancel, issues[1].Object)
+}
+
+func TestValidateUnknownLayout(t *testing.T) {
+	d := DefaultContext()
+	obj, err := DecodeMap(map[string]interface{}{"Type": "*fyne.Container", "Layout": "Spiral"}, d)
+	require.Error(t, err)
+
+	issues := Validate(obj, d)
+	require.Len(t, issues, 1)
+	assert.Equal(t, SeverityWarning, issues[0].Severity)
+	assert.Equal(t, "undefined layout Spiral, using Stack", issues[0].Message)
+	assert.IsType(t, &fyne.Container{}, issues[0].Object)
+}
+
+func TestValidateReservedNames(t *testing.T) {
+	d := DefaultContext()
+	label := widget.NewLabel("")
+	d.Metadata()[label] = map[string]string{"name": "makeUI"}
+	issues := Validate(label, d)
+	require.Len(t, issues, 1)
+	assert.Equal(t, SeverityError, issues[0].Severity)
+	assert.Equal(t, "name makeUI is used for the makeUI method", issues[0].Message)
+
+	save := widget.NewButton("Save", nil)
+	d.Metadata()[save] = map[string]string{"name": "save", "OnTapped": "g.onSave"}
+	status := widget.NewLabel("")
+	d.Metadata()[status] = map[string]string{"name": "onSave"}
+	box := container.NewVBox(save, status)
+	d.Metadata()[box] = map[string]string{"name": "loginGui", "layout": "VBox"}
+
+	issues = ValidateExport(box, d, "login", ExportOptions{})
+	messages := make([]string, len(issues))
+	for i, issue := range issues {
+		messages[i] = issue.String()
+	}
+	assert.Equal(t, []string{
+		"error: *fyne.Container: name loginGui is used for the GUI type",
+		"error: *widget.Label: name onSave is used for the handler of action g.onSave",
+	}, messages)
+	assert.Len(t, Validate(box, d), 1)
+}
@@ -9,66 +9,116 @@ import (
 // WidgetInfo contains the name and corresponding functions for the widget type
 type WidgetInfo = guidefs.WidgetInfo
 
+// DefaultRegistry returns the registry used by contexts that do not have their own.
+// The package level functions such as `RegisterWidget` and `WidgetClassList` work with this registry.
+func DefaultRegistry() *Registry {
+	return guidefs.DefaultRegistry()
+}
+
+// NewRegistry returns a registry that starts with a copy of the types in the default registry.
+// Types can then be registered, replaced or unregistered without affecting other contexts,
+// and the registry used by passing it to `ContextWithRegistry`.
+func NewRegistry() *Registry {
+	return guidefs.DefaultRegistry().Clone()
+}
+
 // CollectionClassList returns the list of supported collection widget classes.
 // These can be used for passing to `CreateNew` or `EditorFor`.
 func CollectionClassList() []string {
-	return guidefs.CollectionNames
+	return guidefs.DefaultRegistry().CollectionNames()
 }
 
 // RegisterCollection allows a 3rd party collection widget to be added to those recognised.
 // It is important that the Name field is populated with the typed name (i.e. *myPkg.MyWidget)
 // and your package should be returned in the list from Packages() as well.
 func RegisterCollection(info WidgetInfo) {
-	guidefs.InitOnce()
-
-	guidefs.CollectionNames = append(guidefs.CollectionNames, info.Name)
-	guidefs.Collections[info.Name] = info
+	guidefs.DefaultRegistry().RegisterCollection(info)
 }
 
 // ContainerClassList returns the list of supported container classes.
 // These can be used for passing to `CreateNew` or `EditorFor`.
 func ContainerClassList() []string {
-	return guidefs.ContainerNames
+	return guidefs.DefaultRegistry().ContainerNames()
 }
 
 // RegisterContainer allows a 3rd party container widget to be added to those recognised.
 // It is important that the Name field is populated with the typed name (i.e. *myPkg.MyWidget)
 // and your package should be returned in the list from Packages() as well.
 func RegisterContainer(info WidgetInfo) {
-	guidefs.InitOnce()
-
-	guidefs.ContainerNames = append(guidefs.ContainerNames, info.Name)
-	guidefs.Containers[info.Name] = info
+	guidefs.DefaultRegistry().RegisterContainer(info)
 }
 
 // GraphicsClassList returns the list of supported graphics primitives classes.
 // These can be used for passing to `CreateNew` or `EditorFor`.
 func GraphicsClassList() []string {
-	return guidefs.GraphicsNames
+	return guidefs.DefaultRegistry().GraphicsNames()
+}
+
+// LayoutInfo contains the functions that create, edit and generate code for a container layout
+type LayoutInfo = guidefs.LayoutInfo
+
+// LayoutList returns the names of the layouts that containers can use.
+// These are stored in the "layout" metadata of a container.
+func LayoutList() []string {
+	return guidefs.DefaultRegistry().LayoutNames()
+}
+
+// RegisterLayout allows a 3rd party layout to be used by containers, with the given name.
+// Any container settings that the layout uses should be stored in the container metadata,
+// so that they are saved with the GUI, and listed in the Properties field for the JSON schema.
+// The Gostring function is required, it can use `ChildrenGoString` to write the code for the children of the container.
+// An error is returned if the layout has no Create or Gostring function.
+func RegisterLayout(name string, info LayoutInfo) error {
+	return guidefs.DefaultRegistry().RegisterLayout(name, info)
+}
+
+// ChildrenGoString returns the Go code for a list of child objects, separated by commas.
+// It is intended for use in the Gostring function of a layout passed to `RegisterLayout`.
+func ChildrenGoString(c Context, defs map[string]string, objs ...fyne.CanvasObject) string {
+	return guidefs.ChildrenGoString(c, defs, objs...)
 }
 
 // WidgetClassList returns the list of supported widget classes.
 // These can be used for passing to `CreateNew` or `EditorFor`.
 func WidgetClassList() []string {
-	return guidefs.WidgetNames
+	return guidefs.DefaultRegistry().WidgetNames()
 }
 
 // RegisterWidget allows a 3rd party Fyne widget to be added to those recognised.
 // It is important that the Name field is populated with the typed name (i.e. *myPkg.MyWidget)
 // and your package should be returned in the list from Packages() as well.
+// Registering a name that is already known replaces its definition.
 func RegisterWidget(info WidgetInfo) {
-	guidefs.InitOnce()
+	guidefs.DefaultRegistry().RegisterWidget(info)
+}
 
-	guidefs.WidgetNames = append(guidefs.WidgetNames, info.Name)
-	guidefs.Widgets[info.Name] = info
+// ReplaceWidget changes the definition of a widget, collection, container or graphic that is already registered
+// with the typed name (i.e. *widget.Button). The previous definition is returned so that the new functions can call it,
+// for example to style a new widget before returning it from Create.
+// If the name is not registered nothing is changed and false is returned.
+func ReplaceWidget(name string, info WidgetInfo) (WidgetInfo, bool) {
+	return guidefs.DefaultRegistry().Replace(name, info)
 }
 
-// DropZonesForObject returns the children of a container that can be used as drag and drop target zones
+// UnregisterWidget removes the widget, collection, container or graphic with the typed name (i.e. *widget.Button)
+// so that it is no longer listed or recognised. It returns false if the name was not registered.
+func UnregisterWidget(name string) bool {
+	return guidefs.DefaultRegistry().Unregister(name)
+}
+
+// DropZonesForObject returns the children of a container that can be used as drag and drop target zones.
+// Only types in the default registry are recognised, use `DropZones` for objects that a context registered.
 func DropZonesForObject(o fyne.CanvasObject) []fyne.CanvasObject {
-	class := guidefs.TypeName(o)
-	info := guidefs.Lookup(class)
+	return DropZones(o, DefaultContext())
+}
+
+// DropZones returns the children of a container that can be used as drag and drop target zones,
+// using the types registered for the context.
+func DropZones(o fyne.CanvasObject, d Context) []fyne.CanvasObject {
+	reg := guidefs.RegistryOf(d)
+	info := reg.Lookup(reg.TypeName(o))
 
-	if !info.IsContainer() {
+	if info == nil || !info.IsContainer() {
 		return nil
 	}
 
@@ -0,0 +1,177 @@
+package refyne
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBadge struct {
+	widget.Label
+}
+
+func TestRegisterWidgetTwice(t *testing.T) {
+	info := WidgetInfo{
+		Name: "*refyne.testBadge",
+		Create: func(Context) fyne.CanvasObject {
+			return &testBadge{}
+		},
+	}
+	RegisterWidget(info)
+	defer UnregisterWidget(info.Name)
+	RegisterWidget(info)
+
+	count := 0
+	for _, name := range WidgetClassList() {
+		if name == info.Name {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count)
+	list := WidgetClassList()
+	assert.True(t, sort.SliceIsSorted(list, func(i, j int) bool {
+		return strings.Split(list[i], ".")[1] < strings.Split(list[j], ".")[1]
+	}))
+
+	assert.True(t, UnregisterWidget(info.Name))
+	assert.NotContains(t, WidgetClassList(), info.Name)
+	assert.Nil(t, CreateNew(info.Name, DefaultContext()))
+	assert.False(t, UnregisterWidget(info.Name))
+}
+
+func TestReplaceWidget(t *testing.T) {
+	_, ok := ReplaceWidget("*refyne.missing", WidgetInfo{Name: "Missing"})
+	assert.False(t, ok)
+	assert.NotContains(t, WidgetClassList(), "*refyne.missing")
+
+	guidefs.InitOnce()
+	info := *guidefs.Lookup("*widget.Button")
+	replaced := info
+	replaced.Create = func(d Context) fyne.CanvasObject {
+		b := info.Create(d).(*widget.Button)
+		b.Importance = widget.HighImportance
+		return b
+	}
+	old, ok := ReplaceWidget("*widget.Button", replaced)
+	require.True(t, ok)
+	defer ReplaceWidget("*widget.Button", old)
+
+	b := CreateNew("*widget.Button", DefaultContext()).(*widget.Button)
+	assert.Equal(t, widget.HighImportance, b.Importance)
+	assert.Contains(t, WidgetClassList(), "*widget.Button")
+}
+
+func TestRegisterWidgetConcurrentLookup(t *testing.T) {
+	guidefs.InitOnce()
+	names := []string{"*refyne.testBadge1", "*refyne.testBadge2", "*refyne.testBadge3"}
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(2)
+		go func(name string) {
+			defer wg.Done()
+			RegisterWidget(WidgetInfo{Name: name})
+		}(name)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_ = guidefs.Lookup("*widget.Label")
+				_ = WidgetClassList()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, name := range names {
+		assert.NotNil(t, guidefs.Lookup(name))
+		assert.True(t, UnregisterWidget(name))
+	}
+}
+
+func TestContextWithRegistry(t *testing.T) {
+	t.Parallel()
+	mobile := NewRegistry()
+	assert.True(t, mobile.Unregister("*widget.Button"))
+	mobile.RegisterWidget(WidgetInfo{
+		Name: "*refyne.testBadge",
+		Create: func(Context) fyne.CanvasObject {
+			return &testBadge{}
+		},
+		Gostring: func(fyne.CanvasObject, Context, map[string]string) string {
+			return "newBadge()"
+		},
+	})
+	mobileCtx := ContextWithRegistry(mobile)
+	desktopCtx := DefaultContext()
+
+	assert.Nil(t, CreateNew("*widget.Button", mobileCtx))
+	assert.NotNil(t, CreateNew("*widget.Button", desktopCtx))
+	assert.NotNil(t, CreateNew("*refyne.testBadge", mobileCtx))
+	assert.Nil(t, CreateNew("*refyne.testBadge", desktopCtx))
+	assert.NotContains(t, mobile.WidgetNames(), "*widget.Button")
+	assert.Contains(t, WidgetClassList(), "*widget.Button")
+	assert.NotContains(t, WidgetClassList(), "*refyne.testBadge")
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, EncodeObject(widget.NewButton("Tap", nil), desktopCtx, buf))
+	obj, _ := DecodeObject(bytes.NewReader(buf.Bytes()), mobileCtx)
+	_, isButton := obj.(*widget.Button)
+	assert.False(t, isButton)
+	obj, err := DecodeObject(bytes.NewReader(buf.Bytes()), desktopCtx)
+	require.NoError(t, err)
+	assert.IsType(t, &widget.Button{}, obj)
+
+	buf.Reset()
+	require.NoError(t, ExportGo(&testBadge{}, mobileCtx, "badge", buf))
+	assert.Contains(t, buf.String(), "newBadge()")
+}
+
+func TestContextRegistryRoundTrip(t *testing.T) {
+	t.Parallel()
+	reg := NewRegistry()
+	reg.RegisterContainer(WidgetInfo{
+		Name: "*refyne.testBadge",
+		Create: func(Context) fyne.CanvasObject {
+			return &testBadge{}
+		},
+		Children: func(fyne.CanvasObject) []fyne.CanvasObject {
+			return []fyne.CanvasObject{}
+		},
+	})
+	d := ContextWithRegistry(reg)
+	badge := &testBadge{}
+	d.Metadata()[badge] = map[string]string{"name": "badge"}
+	root := container.NewVBox(badge)
+	d.Metadata()[root] = map[string]string{"layout": "VBox"}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, EncodeObject(root, d, buf))
+	assert.Contains(t, buf.String(), `"Type": "*refyne.testBadge"`)
+	buf.Reset()
+	require.NoError(t, EncodeObjectWithOptions(root, d, buf, EncodeOptions{Canonical: true}))
+	assert.Contains(t, buf.String(), `"Type": "*refyne.testBadge"`)
+
+	decoded := ContextWithRegistry(reg)
+	obj, err := DecodeObject(bytes.NewReader(buf.Bytes()), decoded)
+	require.NoError(t, err)
+	objs := obj.(*fyne.Container).Objects
+	require.Len(t, objs, 1)
+	assert.IsType(t, &testBadge{}, objs[0])
+	assert.Equal(t, "badge", decoded.Metadata()[objs[0]]["name"])
+	assert.NotNil(t, DropZones(objs[0], decoded))
+
+	schema, err := json.Marshal(JSONSchemaFor(d))
+	require.NoError(t, err)
+	assert.Contains(t, string(schema), "refyne.testBadge")
+}
@@ -0,0 +1,84 @@
+package refyne
+
+import (
+	"encoding/json"
+	"io"
+
+	"fyne.io/fyne/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/fyne-io/refyne/internal/guidefs"
+)
+
+// DecodeObjectYAML returns a tree of `CanvasObject` elements from the provided YAML `Reader`.
+// The document has the same structure as the JSON read by `DecodeObject` and may use comments,
+// anchors and aliases, for example to repeat a component.
+func DecodeObjectYAML(r io.Reader, d Context) (fyne.CanvasObject, error) {
+	return DecodeObjectYAMLWithOptions(r, d, DecodeOptions{})
+}
+
+// DecodeObjectYAMLWithOptions is like `DecodeObjectYAML` but allows the decoding behaviour to be configured.
+func DecodeObjectYAMLWithOptions(r io.Reader, d Context, opts DecodeOptions) (fyne.CanvasObject, error) {
+	guidefs.InitOnce()
+
+	var doc interface{}
+	err := yaml.NewDecoder(r).Decode(&doc)
+	if err == io.EOF || doc == nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// pass through JSON so that numbers and maps have the same types as `DecodeObject` would see
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	if err = json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	return decodeDocument(tree, d, opts)
+}
+
+// EncodeObjectYAML writes a YAML document for the tree of `CanvasObject` elements to the writer.
+// It contains the same data as `EncodeObject`, with the fields in the same order.
+func EncodeObjectYAML(obj fyne.CanvasObject, d Context, w io.Writer) error {
+	return EncodeObjectYAMLWithOptions(obj, d, w, EncodeOptions{})
+}
+
+// EncodeObjectYAMLWithOptions is like `EncodeObjectYAML` but allows the encoding behaviour to be configured.
+func EncodeObjectYAMLWithOptions(obj fyne.CanvasObject, d Context, w io.Writer, opts EncodeOptions) error {
+	doc, err := encodeDocument(obj, d, opts)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	// JSON is valid YAML, so parsing it as a node keeps the field order of the encoded structs
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	clearYAMLStyle(&node)
+
+	e := yaml.NewEncoder(w)
+	e.SetIndent(2)
+	if err = e.Encode(&node); err != nil {
+		return err
+	}
+	return e.Close()
+}
+
+// clearYAMLStyle removes the flow and quoting styles that were parsed from JSON so that block style is written.
+func clearYAMLStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		clearYAMLStyle(child)
+	}
+}
@@ -0,0 +1,80 @@
+package refyne
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeObjectYAML(t *testing.T) {
+	l := widget.NewLabelWithStyle("Hi", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	meta := map[fyne.CanvasObject]map[string]string{l: {"name": "myLabel"}}
+
+	var buf bytes.Buffer
+	err := EncodeObjectYAML(l, &context{meta: meta}, &buf)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(buf.String(), `Version: 1
+Object:
+  Type: '*widget.Label'
+  Name: myLabel
+  Struct:
+    Hidden: false
+    Text: Hi
+    Alignment: 1
+`))
+}
+
+func TestDecodeObjectYAML(t *testing.T) {
+	in := `# a layout with a repeated component
+Version: 1
+Object:
+  Type: "*fyne.Container"
+  Layout: VBox
+  Objects:
+    - &greeting
+      Type: "*widget.Label"
+      Struct:
+        Text: Hi
+        Alignment: 1
+    - *greeting
+`
+	obj, err := DecodeObjectYAML(strings.NewReader(in), DefaultContext())
+	require.NoError(t, err)
+
+	c, ok := obj.(*fyne.Container)
+	require.True(t, ok)
+	require.Len(t, c.Objects, 2)
+	for _, o := range c.Objects {
+		l := o.(*widget.Label)
+		assert.Equal(t, "Hi", l.Text)
+		assert.Equal(t, fyne.TextAlignCenter, l.Alignment)
+	}
+	assert.NotSame(t, c.Objects[0], c.Objects[1])
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	l1 := widget.NewLabelWithStyle("Hi", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	l2 := widget.NewLabel("There")
+	s := container.NewHSplit(l1, l2)
+	s.Offset = 0.25
+	meta := map[fyne.CanvasObject]map[string]string{s: {"name": "mySplit"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeObjectYAML(s, &context{meta: meta}, &buf))
+
+	meta2 := map[fyne.CanvasObject]map[string]string{}
+	obj, err := DecodeObjectYAML(&buf, &context{meta: meta2})
+	require.NoError(t, err)
+
+	var fromYAML, fromJSON bytes.Buffer
+	require.NoError(t, EncodeObject(obj, &context{meta: meta2}, &fromYAML))
+	require.NoError(t, EncodeObject(s, &context{meta: meta}, &fromJSON))
+	assert.Equal(t, fromJSON.String(), fromYAML.String())
+}